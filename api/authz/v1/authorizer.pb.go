@@ -0,0 +1,1600 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: api/authz/v1/authorizer.proto
+
+package authz
+
+import (
+	v2 "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	status "google.golang.org/genproto/googleapis/rpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// WatchTrigger is what made a watch evaluate its decisions.
+type WatchTrigger int32
+
+const (
+	WatchTrigger_WATCH_TRIGGER_UNKNOWN WatchTrigger = 0
+	// the first evaluation of the watch.
+	WatchTrigger_WATCH_TRIGGER_INITIAL WatchTrigger = 1
+	// a bundle activated in the runtime of the watch.
+	WatchTrigger_WATCH_TRIGGER_BUNDLE WatchTrigger = 2
+	// objects, relations or types of the edge directory changed.
+	WatchTrigger_WATCH_TRIGGER_DIRECTORY WatchTrigger = 3
+	// the watch interval elapsed.
+	WatchTrigger_WATCH_TRIGGER_INTERVAL WatchTrigger = 4
+)
+
+// Enum value maps for WatchTrigger.
+var (
+	WatchTrigger_name = map[int32]string{
+		0: "WATCH_TRIGGER_UNKNOWN",
+		1: "WATCH_TRIGGER_INITIAL",
+		2: "WATCH_TRIGGER_BUNDLE",
+		3: "WATCH_TRIGGER_DIRECTORY",
+		4: "WATCH_TRIGGER_INTERVAL",
+	}
+	WatchTrigger_value = map[string]int32{
+		"WATCH_TRIGGER_UNKNOWN":   0,
+		"WATCH_TRIGGER_INITIAL":   1,
+		"WATCH_TRIGGER_BUNDLE":    2,
+		"WATCH_TRIGGER_DIRECTORY": 3,
+		"WATCH_TRIGGER_INTERVAL":  4,
+	}
+)
+
+func (x WatchTrigger) Enum() *WatchTrigger {
+	p := new(WatchTrigger)
+	*p = x
+	return p
+}
+
+func (x WatchTrigger) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WatchTrigger) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_authz_v1_authorizer_proto_enumTypes[0].Descriptor()
+}
+
+func (WatchTrigger) Type() protoreflect.EnumType {
+	return &file_api_authz_v1_authorizer_proto_enumTypes[0]
+}
+
+func (x WatchTrigger) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WatchTrigger.Descriptor instead.
+func (WatchTrigger) EnumDescriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{0}
+}
+
+type IsWithCompanionsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Decisions []*v2.Decision `protobuf:"bytes,1,rep,name=decisions,proto3" json:"decisions,omitempty"`
+	// reasons the policy supplied for the decisions through its <decision>_reasons rules, by decision.
+	Reasons *structpb.Struct `protobuf:"bytes,2,opt,name=reasons,proto3" json:"reasons,omitempty"`
+	// value of the obligations rule of the policy.
+	Obligations *structpb.Value `protobuf:"bytes,3,opt,name=obligations,proto3" json:"obligations,omitempty"`
+}
+
+func (x *IsWithCompanionsResponse) Reset() {
+	*x = IsWithCompanionsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsWithCompanionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsWithCompanionsResponse) ProtoMessage() {}
+
+func (x *IsWithCompanionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsWithCompanionsResponse.ProtoReflect.Descriptor instead.
+func (*IsWithCompanionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IsWithCompanionsResponse) GetDecisions() []*v2.Decision {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
+func (x *IsWithCompanionsResponse) GetReasons() *structpb.Struct {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+func (x *IsWithCompanionsResponse) GetObligations() *structpb.Value {
+	if x != nil {
+		return x.Obligations
+	}
+	return nil
+}
+
+type IsBatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// policy context of the items, its path applies to the items that do not set their own.
+	PolicyContext   *api.PolicyContext   `protobuf:"bytes,1,opt,name=policy_context,json=policyContext,proto3" json:"policy_context,omitempty"`
+	IdentityContext *api.IdentityContext `protobuf:"bytes,2,opt,name=identity_context,json=identityContext,proto3" json:"identity_context,omitempty"`
+	PolicyInstance  *api.PolicyInstance  `protobuf:"bytes,3,opt,name=policy_instance,json=policyInstance,proto3,oneof" json:"policy_instance,omitempty"`
+	Items           []*IsBatchItem       `protobuf:"bytes,4,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *IsBatchRequest) Reset() {
+	*x = IsBatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsBatchRequest) ProtoMessage() {}
+
+func (x *IsBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsBatchRequest.ProtoReflect.Descriptor instead.
+func (*IsBatchRequest) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IsBatchRequest) GetPolicyContext() *api.PolicyContext {
+	if x != nil {
+		return x.PolicyContext
+	}
+	return nil
+}
+
+func (x *IsBatchRequest) GetIdentityContext() *api.IdentityContext {
+	if x != nil {
+		return x.IdentityContext
+	}
+	return nil
+}
+
+func (x *IsBatchRequest) GetPolicyInstance() *api.PolicyInstance {
+	if x != nil {
+		return x.PolicyInstance
+	}
+	return nil
+}
+
+func (x *IsBatchRequest) GetItems() []*IsBatchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type IsBatchItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// policy path of the item, overrides the path of the policy context.
+	Path            string           `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	ResourceContext *structpb.Struct `protobuf:"bytes,2,opt,name=resource_context,json=resourceContext,proto3" json:"resource_context,omitempty"`
+}
+
+func (x *IsBatchItem) Reset() {
+	*x = IsBatchItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsBatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsBatchItem) ProtoMessage() {}
+
+func (x *IsBatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsBatchItem.ProtoReflect.Descriptor instead.
+func (*IsBatchItem) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IsBatchItem) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *IsBatchItem) GetResourceContext() *structpb.Struct {
+	if x != nil {
+		return x.ResourceContext
+	}
+	return nil
+}
+
+type IsBatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// results of the items, in the order of the request items.
+	Results []*IsBatchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *IsBatchResponse) Reset() {
+	*x = IsBatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsBatchResponse) ProtoMessage() {}
+
+func (x *IsBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsBatchResponse.ProtoReflect.Descriptor instead.
+func (*IsBatchResponse) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *IsBatchResponse) GetResults() []*IsBatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type IsBatchResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Decisions []*v2.Decision `protobuf:"bytes,1,rep,name=decisions,proto3" json:"decisions,omitempty"`
+	// error of the item, when its evaluation failed.
+	Error *status.Status `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// reasons the policy supplied for the decisions through its <decision>_reasons rules, by decision.
+	Reasons *structpb.Struct `protobuf:"bytes,3,opt,name=reasons,proto3" json:"reasons,omitempty"`
+	// value of the obligations rule of the policy.
+	Obligations *structpb.Value `protobuf:"bytes,4,opt,name=obligations,proto3" json:"obligations,omitempty"`
+}
+
+func (x *IsBatchResult) Reset() {
+	*x = IsBatchResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IsBatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IsBatchResult) ProtoMessage() {}
+
+func (x *IsBatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IsBatchResult.ProtoReflect.Descriptor instead.
+func (*IsBatchResult) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *IsBatchResult) GetDecisions() []*v2.Decision {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
+func (x *IsBatchResult) GetError() *status.Status {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *IsBatchResult) GetReasons() *structpb.Struct {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+func (x *IsBatchResult) GetObligations() *structpb.Value {
+	if x != nil {
+		return x.Obligations
+	}
+	return nil
+}
+
+type WatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// results of the items, in the order of the request items.
+	Results []*IsBatchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// indexes of the items whose results changed since the previous response, every item in the first response.
+	Changed []int32      `protobuf:"varint,2,rep,packed,name=changed,proto3" json:"changed,omitempty"`
+	Trigger WatchTrigger `protobuf:"varint,3,opt,name=trigger,proto3,enum=topaz.authz.v1.WatchTrigger" json:"trigger,omitempty"`
+}
+
+func (x *WatchResponse) Reset() {
+	*x = WatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResponse) ProtoMessage() {}
+
+func (x *WatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResponse.ProtoReflect.Descriptor instead.
+func (*WatchResponse) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchResponse) GetResults() []*IsBatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetChanged() []int32 {
+	if x != nil {
+		return x.Changed
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetTrigger() WatchTrigger {
+	if x != nil {
+		return x.Trigger
+	}
+	return WatchTrigger_WATCH_TRIGGER_UNKNOWN
+}
+
+type ExplainResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Decisions []*v2.Decision `protobuf:"bytes,1,rep,name=decisions,proto3" json:"decisions,omitempty"`
+	// explanations of the decisions, in the order of the decisions.
+	Explanations []*DecisionExplanation `protobuf:"bytes,2,rep,name=explanations,proto3" json:"explanations,omitempty"`
+}
+
+func (x *ExplainResponse) Reset() {
+	*x = ExplainResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExplainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainResponse) ProtoMessage() {}
+
+func (x *ExplainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainResponse.ProtoReflect.Descriptor instead.
+func (*ExplainResponse) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ExplainResponse) GetDecisions() []*v2.Decision {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
+func (x *ExplainResponse) GetExplanations() []*DecisionExplanation {
+	if x != nil {
+		return x.Explanations
+	}
+	return nil
+}
+
+// DecisionExplanation lists the rules evaluated for a decision.
+type DecisionExplanation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Decision string `protobuf:"bytes,1,opt,name=decision,proto3" json:"decision,omitempty"`
+	Is       bool   `protobuf:"varint,2,opt,name=is,proto3" json:"is,omitempty"`
+	// rules of the decision, in the order they appear in the policy.
+	Rules []*RuleExplanation `protobuf:"bytes,3,rep,name=rules,proto3" json:"rules,omitempty"`
+}
+
+func (x *DecisionExplanation) Reset() {
+	*x = DecisionExplanation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DecisionExplanation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionExplanation) ProtoMessage() {}
+
+func (x *DecisionExplanation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionExplanation.ProtoReflect.Descriptor instead.
+func (*DecisionExplanation) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DecisionExplanation) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *DecisionExplanation) GetIs() bool {
+	if x != nil {
+		return x.Is
+	}
+	return false
+}
+
+func (x *DecisionExplanation) GetRules() []*RuleExplanation {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+// RuleExplanation is the outcome of a rule of a decision.
+type RuleExplanation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// head of the rule, such as "allowed = true".
+	Rule string `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	// location of the rule, as file:row.
+	Location string `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	// default is set for the default rule of the decision.
+	Default bool `protobuf:"varint,3,opt,name=default,proto3" json:"default,omitempty"`
+	// fired is set when the body of the rule succeeded.
+	Fired bool `protobuf:"varint,4,opt,name=fired,proto3" json:"fired,omitempty"`
+	// failed_expression is the expression of the body that failed last, when the rule did not fire.
+	FailedExpression string `protobuf:"bytes,5,opt,name=failed_expression,json=failedExpression,proto3" json:"failed_expression,omitempty"`
+	// location of the failed expression, as file:row.
+	FailedLocation string `protobuf:"bytes,6,opt,name=failed_location,json=failedLocation,proto3" json:"failed_location,omitempty"`
+	// ds builtin calls made by the rule, directly or through the rules it depends on.
+	DsCalls []*BuiltinCall `protobuf:"bytes,7,rep,name=ds_calls,json=dsCalls,proto3" json:"ds_calls,omitempty"`
+	// skipped is set when the rule index ruled the rule out without evaluating its body,
+	// because a condition of the body does not match the input.
+	Skipped bool `protobuf:"varint,8,opt,name=skipped,proto3" json:"skipped,omitempty"`
+}
+
+func (x *RuleExplanation) Reset() {
+	*x = RuleExplanation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RuleExplanation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleExplanation) ProtoMessage() {}
+
+func (x *RuleExplanation) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleExplanation.ProtoReflect.Descriptor instead.
+func (*RuleExplanation) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RuleExplanation) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *RuleExplanation) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *RuleExplanation) GetDefault() bool {
+	if x != nil {
+		return x.Default
+	}
+	return false
+}
+
+func (x *RuleExplanation) GetFired() bool {
+	if x != nil {
+		return x.Fired
+	}
+	return false
+}
+
+func (x *RuleExplanation) GetFailedExpression() string {
+	if x != nil {
+		return x.FailedExpression
+	}
+	return ""
+}
+
+func (x *RuleExplanation) GetFailedLocation() string {
+	if x != nil {
+		return x.FailedLocation
+	}
+	return ""
+}
+
+func (x *RuleExplanation) GetDsCalls() []*BuiltinCall {
+	if x != nil {
+		return x.DsCalls
+	}
+	return nil
+}
+
+func (x *RuleExplanation) GetSkipped() bool {
+	if x != nil {
+		return x.Skipped
+	}
+	return false
+}
+
+// BuiltinCall is a ds builtin call that reached the directory.
+type BuiltinCall struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Builtin string          `protobuf:"bytes,1,opt,name=builtin,proto3" json:"builtin,omitempty"`
+	Args    *structpb.Value `protobuf:"bytes,2,opt,name=args,proto3" json:"args,omitempty"`
+	Result  *structpb.Value `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	Error   string          `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *BuiltinCall) Reset() {
+	*x = BuiltinCall{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BuiltinCall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BuiltinCall) ProtoMessage() {}
+
+func (x *BuiltinCall) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BuiltinCall.ProtoReflect.Descriptor instead.
+func (*BuiltinCall) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BuiltinCall) GetBuiltin() string {
+	if x != nil {
+		return x.Builtin
+	}
+	return ""
+}
+
+func (x *BuiltinCall) GetArgs() *structpb.Value {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *BuiltinCall) GetResult() *structpb.Value {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BuiltinCall) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// SearchDecisionsRequest selects logged decisions. Decisions are returned when they match
+// all the filters that are set.
+type SearchDecisionsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// decisions at or after this time.
+	From *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	// decisions before this time.
+	To *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// user id or email of the decisions.
+	User string `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	// glob on the policy path of the decisions, "*" matches a single path segment and "**" any number of segments.
+	Path string `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	// allow, deny or none for records without decisions, such as those of query and compile calls.
+	Outcome  string `protobuf:"bytes,5,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	TenantId string `protobuf:"bytes,6,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	// maximum number of decisions returned, 100 when not set.
+	Limit int32 `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`
+	// returns the oldest decisions first instead of the most recent ones.
+	OldestFirst bool `protobuf:"varint,8,opt,name=oldest_first,json=oldestFirst,proto3" json:"oldest_first,omitempty"`
+	// continues a previous search with the same filters and order after its last decision.
+	PageToken      string              `protobuf:"bytes,9,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	PolicyInstance *api.PolicyInstance `protobuf:"bytes,10,opt,name=policy_instance,json=policyInstance,proto3,oneof" json:"policy_instance,omitempty"`
+}
+
+func (x *SearchDecisionsRequest) Reset() {
+	*x = SearchDecisionsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchDecisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchDecisionsRequest) ProtoMessage() {}
+
+func (x *SearchDecisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchDecisionsRequest.ProtoReflect.Descriptor instead.
+func (*SearchDecisionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SearchDecisionsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *SearchDecisionsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *SearchDecisionsRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *SearchDecisionsRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SearchDecisionsRequest) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *SearchDecisionsRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *SearchDecisionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchDecisionsRequest) GetOldestFirst() bool {
+	if x != nil {
+		return x.OldestFirst
+	}
+	return false
+}
+
+func (x *SearchDecisionsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *SearchDecisionsRequest) GetPolicyInstance() *api.PolicyInstance {
+	if x != nil {
+		return x.PolicyInstance
+	}
+	return nil
+}
+
+type SearchDecisionsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Decisions []*api.Decision `protobuf:"bytes,1,rep,name=decisions,proto3" json:"decisions,omitempty"`
+	// page token of the position of the last decision returned, empty when no decision was returned.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *SearchDecisionsResponse) Reset() {
+	*x = SearchDecisionsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchDecisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchDecisionsResponse) ProtoMessage() {}
+
+func (x *SearchDecisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchDecisionsResponse.ProtoReflect.Descriptor instead.
+func (*SearchDecisionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SearchDecisionsResponse) GetDecisions() []*api.Decision {
+	if x != nil {
+		return x.Decisions
+	}
+	return nil
+}
+
+func (x *SearchDecisionsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// FlushDecisionCacheRequest selects the cached decisions to remove. Decisions are removed
+// when they match all the filters that are set, all decisions are removed when none is set.
+type FlushDecisionCacheRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// user of the decisions, matched against the id of the resolved user and the identity of the identity context.
+	User string `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	// fields of the resource context of the decisions, such as the type and id of an object.
+	ResourceContext *structpb.Struct `protobuf:"bytes,2,opt,name=resource_context,json=resourceContext,proto3" json:"resource_context,omitempty"`
+}
+
+func (x *FlushDecisionCacheRequest) Reset() {
+	*x = FlushDecisionCacheRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlushDecisionCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlushDecisionCacheRequest) ProtoMessage() {}
+
+func (x *FlushDecisionCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlushDecisionCacheRequest.ProtoReflect.Descriptor instead.
+func (*FlushDecisionCacheRequest) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *FlushDecisionCacheRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *FlushDecisionCacheRequest) GetResourceContext() *structpb.Struct {
+	if x != nil {
+		return x.ResourceContext
+	}
+	return nil
+}
+
+type FlushDecisionCacheResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// number of decisions removed.
+	Flushed int32 `protobuf:"varint,1,opt,name=flushed,proto3" json:"flushed,omitempty"`
+}
+
+func (x *FlushDecisionCacheResponse) Reset() {
+	*x = FlushDecisionCacheResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_authz_v1_authorizer_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FlushDecisionCacheResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlushDecisionCacheResponse) ProtoMessage() {}
+
+func (x *FlushDecisionCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_authz_v1_authorizer_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlushDecisionCacheResponse.ProtoReflect.Descriptor instead.
+func (*FlushDecisionCacheResponse) Descriptor() ([]byte, []int) {
+	return file_api_authz_v1_authorizer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FlushDecisionCacheResponse) GetFlushed() int32 {
+	if x != nil {
+		return x.Flushed
+	}
+	return 0
+}
+
+var File_api_authz_v1_authorizer_proto protoreflect.FileDescriptor
+
+var file_api_authz_v1_authorizer_proto_rawDesc = []byte{
+	0x0a, 0x1d, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2f, 0x76, 0x31, 0x2f, 0x61,
+	0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x0e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x1a,
+	0x2c, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a,
+	0x65, 0x72, 0x2f, 0x76, 0x32, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x5f, 0x6c, 0x6f, 0x67, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2f, 0x61,
+	0x73, 0x65, 0x72, 0x74, 0x6f, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72,
+	0x2f, 0x76, 0x32, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79,
+	0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2d,
+	0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65,
+	0x72, 0x2f, 0x76, 0x32, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x5f,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2e, 0x61,
+	0x73, 0x65, 0x72, 0x74, 0x6f, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72,
+	0x2f, 0x76, 0x32, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x5f, 0x69,
+	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x25, 0x61,
+	0x73, 0x65, 0x72, 0x74, 0x6f, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72,
+	0x2f, 0x76, 0x32, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69,
+	0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x17, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xc5, 0x01, 0x0a, 0x18, 0x49,
+	0x73, 0x57, 0x69, 0x74, 0x68, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x09, 0x64, 0x65, 0x63, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x61, 0x73, 0x65,
+	0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76,
+	0x32, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x64, 0x65, 0x63, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x31, 0x0a, 0x07, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52,
+	0x07, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x38, 0x0a, 0x0b, 0x6f, 0x62, 0x6c, 0x69,
+	0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0b, 0x6f, 0x62, 0x6c, 0x69, 0x67, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x22, 0xd5, 0x02, 0x0a, 0x0e, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x4e, 0x0a, 0x0e, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x5f,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e,
+	0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65,
+	0x72, 0x2e, 0x76, 0x32, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x0d, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x54, 0x0a, 0x10, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74,
+	0x79, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x29, 0x2e, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69,
+	0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x49, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x74, 0x79, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x0f, 0x69, 0x64, 0x65, 0x6e,
+	0x74, 0x69, 0x74, 0x79, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x56, 0x0a, 0x0f, 0x70,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75,
+	0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x48, 0x00,
+	0x52, 0x0e, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a,
+	0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x52,
+	0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x65, 0x0a, 0x0b, 0x49, 0x73,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74,
+	0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x42, 0x0a,
+	0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74,
+	0x52, 0x0f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x22, 0x4a, 0x0a, 0x0f, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75,
+	0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0xe4, 0x01,
+	0x0a, 0x0d, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
+	0x3c, 0x0a, 0x09, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68,
+	0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x09, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x28, 0x0a,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x31, 0x0a, 0x07, 0x72, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63,
+	0x74, 0x52, 0x07, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x38, 0x0a, 0x0b, 0x6f, 0x62,
+	0x6c, 0x69, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0b, 0x6f, 0x62, 0x6c, 0x69, 0x67, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x22, 0x9a, 0x01, 0x0a, 0x0d, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e,
+	0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x05,
+	0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x12, 0x36, 0x0a, 0x07, 0x74, 0x72, 0x69,
+	0x67, 0x67, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x74, 0x6f, 0x70,
+	0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x52, 0x07, 0x74, 0x72, 0x69, 0x67, 0x67, 0x65,
+	0x72, 0x22, 0x98, 0x01, 0x0a, 0x0f, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x09, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x61, 0x73, 0x65, 0x72, 0x74,
+	0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32, 0x2e,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x47, 0x0a, 0x0c, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x74, 0x6f, 0x70, 0x61,
+	0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c,
+	0x65, 0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x78, 0x0a, 0x13,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x69, 0x73, 0x12,
+	0x35, 0x0a, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e,
+	0x52, 0x75, 0x6c, 0x65, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x22, 0x99, 0x02, 0x0a, 0x0f, 0x52, 0x75, 0x6c, 0x65, 0x45,
+	0x78, 0x70, 0x6c, 0x61, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x75,
+	0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65,
+	0x66, 0x61, 0x75, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x65, 0x66,
+	0x61, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x72, 0x65, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x05, 0x66, 0x69, 0x72, 0x65, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x66, 0x61,
+	0x69, 0x6c, 0x65, 0x64, 0x5f, 0x65, 0x78, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x45, 0x78, 0x70,
+	0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x66, 0x61, 0x69, 0x6c, 0x65,
+	0x64, 0x5f, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0e, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x36, 0x0a, 0x08, 0x64, 0x73, 0x5f, 0x63, 0x61, 0x6c, 0x6c, 0x73, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a,
+	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x75, 0x69, 0x6c, 0x74, 0x69, 0x6e, 0x43, 0x61, 0x6c, 0x6c, 0x52,
+	0x07, 0x64, 0x73, 0x43, 0x61, 0x6c, 0x6c, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x6b, 0x69, 0x70,
+	0x70, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x6b, 0x69, 0x70, 0x70,
+	0x65, 0x64, 0x22, 0x99, 0x01, 0x0a, 0x0b, 0x42, 0x75, 0x69, 0x6c, 0x74, 0x69, 0x6e, 0x43, 0x61,
+	0x6c, 0x6c, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x75, 0x69, 0x6c, 0x74, 0x69, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x75, 0x69, 0x6c, 0x74, 0x69, 0x6e, 0x12, 0x2a, 0x0a, 0x04,
+	0x61, 0x72, 0x67, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x12, 0x2e, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x97,
+	0x03, 0x0a, 0x16, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x66, 0x72, 0x6f,
+	0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x2a, 0x0a, 0x02, 0x74, 0x6f, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74,
+	0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x18, 0x0a,
+	0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x65, 0x6e, 0x61, 0x6e,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x65, 0x6e, 0x61,
+	0x6e, 0x74, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x6c,
+	0x64, 0x65, 0x73, 0x74, 0x5f, 0x66, 0x69, 0x72, 0x73, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0b, 0x6f, 0x6c, 0x64, 0x65, 0x73, 0x74, 0x46, 0x69, 0x72, 0x73, 0x74, 0x12, 0x1d, 0x0a,
+	0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x56, 0x0a, 0x0f,
+	0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x5f, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61,
+	0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x48,
+	0x00, 0x52, 0x0e, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x88, 0x01, 0x01, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x5f,
+	0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x22, 0x83, 0x01, 0x0a, 0x17, 0x53, 0x65, 0x61,
+	0x72, 0x63, 0x68, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x40, 0x0a, 0x09, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f,
+	0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x64, 0x65, 0x63,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70,
+	0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x73,
+	0x0a, 0x19, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x43,
+	0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75,
+	0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12,
+	0x42, 0x0a, 0x10, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75,
+	0x63, 0x74, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x74,
+	0x65, 0x78, 0x74, 0x22, 0x36, 0x0a, 0x1a, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x44, 0x65, 0x63, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x65, 0x64, 0x2a, 0x97, 0x01, 0x0a, 0x0c,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x54, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x12, 0x19, 0x0a, 0x15,
+	0x57, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x52, 0x49, 0x47, 0x47, 0x45, 0x52, 0x5f, 0x55, 0x4e,
+	0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x57, 0x41, 0x54, 0x43, 0x48,
+	0x5f, 0x54, 0x52, 0x49, 0x47, 0x47, 0x45, 0x52, 0x5f, 0x49, 0x4e, 0x49, 0x54, 0x49, 0x41, 0x4c,
+	0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x57, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x52, 0x49, 0x47,
+	0x47, 0x45, 0x52, 0x5f, 0x42, 0x55, 0x4e, 0x44, 0x4c, 0x45, 0x10, 0x02, 0x12, 0x1b, 0x0a, 0x17,
+	0x57, 0x41, 0x54, 0x43, 0x48, 0x5f, 0x54, 0x52, 0x49, 0x47, 0x47, 0x45, 0x52, 0x5f, 0x44, 0x49,
+	0x52, 0x45, 0x43, 0x54, 0x4f, 0x52, 0x59, 0x10, 0x03, 0x12, 0x1a, 0x0a, 0x16, 0x57, 0x41, 0x54,
+	0x43, 0x48, 0x5f, 0x54, 0x52, 0x49, 0x47, 0x47, 0x45, 0x52, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x52,
+	0x56, 0x41, 0x4c, 0x10, 0x04, 0x32, 0xf2, 0x05, 0x0a, 0x0a, 0x41, 0x75, 0x74, 0x68, 0x6f, 0x72,
+	0x69, 0x7a, 0x65, 0x72, 0x12, 0x85, 0x01, 0x0a, 0x10, 0x49, 0x73, 0x57, 0x69, 0x74, 0x68, 0x43,
+	0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1f, 0x2e, 0x61, 0x73, 0x65, 0x72,
+	0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72, 0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32,
+	0x2e, 0x49, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x74, 0x6f, 0x70,
+	0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x57, 0x69,
+	0x74, 0x68, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x26, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x3a, 0x01, 0x2a, 0x22,
+	0x1b, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x32, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2f, 0x69,
+	0x73, 0x2f, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x6e, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x6d, 0x0a, 0x07,
+	0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1e, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e,
+	0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e,
+	0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b,
+	0x3a, 0x01, 0x2a, 0x22, 0x16, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x32, 0x2f, 0x61, 0x75, 0x74,
+	0x68, 0x7a, 0x2f, 0x69, 0x73, 0x2f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x12, 0x68, 0x0a, 0x05, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x1e, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74,
+	0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x73, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74,
+	0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x1e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x18, 0x3a, 0x01, 0x2a, 0x22, 0x13,
+	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x32, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2f, 0x77, 0x61,
+	0x74, 0x63, 0x68, 0x30, 0x01, 0x12, 0x70, 0x0a, 0x07, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e,
+	0x12, 0x1f, 0x2e, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72,
+	0x69, 0x7a, 0x65, 0x72, 0x2e, 0x76, 0x32, 0x2e, 0x49, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1f, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e,
+	0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x3a, 0x01, 0x2a, 0x22, 0x18, 0x2f,
+	0x61, 0x70, 0x69, 0x2f, 0x76, 0x32, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2f, 0x69, 0x73, 0x2f,
+	0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x12, 0x7d, 0x0a, 0x0f, 0x53, 0x65, 0x61, 0x72, 0x63,
+	0x68, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x26, 0x2e, 0x74, 0x6f, 0x70,
+	0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x72,
+	0x63, 0x68, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x27, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x19, 0x82, 0xd3, 0xe4,
+	0x93, 0x02, 0x13, 0x12, 0x11, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x32, 0x2f, 0x64, 0x65, 0x63,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x91, 0x01, 0x0a, 0x12, 0x46, 0x6c, 0x75, 0x73, 0x68,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x12, 0x29, 0x2e,
+	0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x46,
+	0x6c, 0x75, 0x73, 0x68, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x63, 0x68,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e, 0x74, 0x6f, 0x70, 0x61, 0x7a,
+	0x2e, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x44,
+	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x3a, 0x01, 0x2a, 0x22,
+	0x19, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x32, 0x2f, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x2f, 0x63,
+	0x61, 0x63, 0x68, 0x65, 0x2f, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x42, 0x30, 0x5a, 0x2e, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x73, 0x65, 0x72, 0x74, 0x6f, 0x2d,
+	0x64, 0x65, 0x76, 0x2f, 0x74, 0x6f, 0x70, 0x61, 0x7a, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x75,
+	0x74, 0x68, 0x7a, 0x2f, 0x76, 0x31, 0x3b, 0x61, 0x75, 0x74, 0x68, 0x7a, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_api_authz_v1_authorizer_proto_rawDescOnce sync.Once
+	file_api_authz_v1_authorizer_proto_rawDescData = file_api_authz_v1_authorizer_proto_rawDesc
+)
+
+func file_api_authz_v1_authorizer_proto_rawDescGZIP() []byte {
+	file_api_authz_v1_authorizer_proto_rawDescOnce.Do(func() {
+		file_api_authz_v1_authorizer_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_authz_v1_authorizer_proto_rawDescData)
+	})
+	return file_api_authz_v1_authorizer_proto_rawDescData
+}
+
+var file_api_authz_v1_authorizer_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_authz_v1_authorizer_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_api_authz_v1_authorizer_proto_goTypes = []interface{}{
+	(WatchTrigger)(0),                  // 0: topaz.authz.v1.WatchTrigger
+	(*IsWithCompanionsResponse)(nil),   // 1: topaz.authz.v1.IsWithCompanionsResponse
+	(*IsBatchRequest)(nil),             // 2: topaz.authz.v1.IsBatchRequest
+	(*IsBatchItem)(nil),                // 3: topaz.authz.v1.IsBatchItem
+	(*IsBatchResponse)(nil),            // 4: topaz.authz.v1.IsBatchResponse
+	(*IsBatchResult)(nil),              // 5: topaz.authz.v1.IsBatchResult
+	(*WatchResponse)(nil),              // 6: topaz.authz.v1.WatchResponse
+	(*ExplainResponse)(nil),            // 7: topaz.authz.v1.ExplainResponse
+	(*DecisionExplanation)(nil),        // 8: topaz.authz.v1.DecisionExplanation
+	(*RuleExplanation)(nil),            // 9: topaz.authz.v1.RuleExplanation
+	(*BuiltinCall)(nil),                // 10: topaz.authz.v1.BuiltinCall
+	(*SearchDecisionsRequest)(nil),     // 11: topaz.authz.v1.SearchDecisionsRequest
+	(*SearchDecisionsResponse)(nil),    // 12: topaz.authz.v1.SearchDecisionsResponse
+	(*FlushDecisionCacheRequest)(nil),  // 13: topaz.authz.v1.FlushDecisionCacheRequest
+	(*FlushDecisionCacheResponse)(nil), // 14: topaz.authz.v1.FlushDecisionCacheResponse
+	(*v2.Decision)(nil),                // 15: aserto.authorizer.v2.Decision
+	(*structpb.Struct)(nil),            // 16: google.protobuf.Struct
+	(*structpb.Value)(nil),             // 17: google.protobuf.Value
+	(*api.PolicyContext)(nil),          // 18: aserto.authorizer.v2.api.PolicyContext
+	(*api.IdentityContext)(nil),        // 19: aserto.authorizer.v2.api.IdentityContext
+	(*api.PolicyInstance)(nil),         // 20: aserto.authorizer.v2.api.PolicyInstance
+	(*status.Status)(nil),              // 21: google.rpc.Status
+	(*timestamppb.Timestamp)(nil),      // 22: google.protobuf.Timestamp
+	(*api.Decision)(nil),               // 23: aserto.authorizer.v2.api.Decision
+	(*v2.IsRequest)(nil),               // 24: aserto.authorizer.v2.IsRequest
+}
+var file_api_authz_v1_authorizer_proto_depIdxs = []int32{
+	15, // 0: topaz.authz.v1.IsWithCompanionsResponse.decisions:type_name -> aserto.authorizer.v2.Decision
+	16, // 1: topaz.authz.v1.IsWithCompanionsResponse.reasons:type_name -> google.protobuf.Struct
+	17, // 2: topaz.authz.v1.IsWithCompanionsResponse.obligations:type_name -> google.protobuf.Value
+	18, // 3: topaz.authz.v1.IsBatchRequest.policy_context:type_name -> aserto.authorizer.v2.api.PolicyContext
+	19, // 4: topaz.authz.v1.IsBatchRequest.identity_context:type_name -> aserto.authorizer.v2.api.IdentityContext
+	20, // 5: topaz.authz.v1.IsBatchRequest.policy_instance:type_name -> aserto.authorizer.v2.api.PolicyInstance
+	3,  // 6: topaz.authz.v1.IsBatchRequest.items:type_name -> topaz.authz.v1.IsBatchItem
+	16, // 7: topaz.authz.v1.IsBatchItem.resource_context:type_name -> google.protobuf.Struct
+	5,  // 8: topaz.authz.v1.IsBatchResponse.results:type_name -> topaz.authz.v1.IsBatchResult
+	15, // 9: topaz.authz.v1.IsBatchResult.decisions:type_name -> aserto.authorizer.v2.Decision
+	21, // 10: topaz.authz.v1.IsBatchResult.error:type_name -> google.rpc.Status
+	16, // 11: topaz.authz.v1.IsBatchResult.reasons:type_name -> google.protobuf.Struct
+	17, // 12: topaz.authz.v1.IsBatchResult.obligations:type_name -> google.protobuf.Value
+	5,  // 13: topaz.authz.v1.WatchResponse.results:type_name -> topaz.authz.v1.IsBatchResult
+	0,  // 14: topaz.authz.v1.WatchResponse.trigger:type_name -> topaz.authz.v1.WatchTrigger
+	15, // 15: topaz.authz.v1.ExplainResponse.decisions:type_name -> aserto.authorizer.v2.Decision
+	8,  // 16: topaz.authz.v1.ExplainResponse.explanations:type_name -> topaz.authz.v1.DecisionExplanation
+	9,  // 17: topaz.authz.v1.DecisionExplanation.rules:type_name -> topaz.authz.v1.RuleExplanation
+	10, // 18: topaz.authz.v1.RuleExplanation.ds_calls:type_name -> topaz.authz.v1.BuiltinCall
+	17, // 19: topaz.authz.v1.BuiltinCall.args:type_name -> google.protobuf.Value
+	17, // 20: topaz.authz.v1.BuiltinCall.result:type_name -> google.protobuf.Value
+	22, // 21: topaz.authz.v1.SearchDecisionsRequest.from:type_name -> google.protobuf.Timestamp
+	22, // 22: topaz.authz.v1.SearchDecisionsRequest.to:type_name -> google.protobuf.Timestamp
+	20, // 23: topaz.authz.v1.SearchDecisionsRequest.policy_instance:type_name -> aserto.authorizer.v2.api.PolicyInstance
+	23, // 24: topaz.authz.v1.SearchDecisionsResponse.decisions:type_name -> aserto.authorizer.v2.api.Decision
+	16, // 25: topaz.authz.v1.FlushDecisionCacheRequest.resource_context:type_name -> google.protobuf.Struct
+	24, // 26: topaz.authz.v1.Authorizer.IsWithCompanions:input_type -> aserto.authorizer.v2.IsRequest
+	2,  // 27: topaz.authz.v1.Authorizer.IsBatch:input_type -> topaz.authz.v1.IsBatchRequest
+	2,  // 28: topaz.authz.v1.Authorizer.Watch:input_type -> topaz.authz.v1.IsBatchRequest
+	24, // 29: topaz.authz.v1.Authorizer.Explain:input_type -> aserto.authorizer.v2.IsRequest
+	11, // 30: topaz.authz.v1.Authorizer.SearchDecisions:input_type -> topaz.authz.v1.SearchDecisionsRequest
+	13, // 31: topaz.authz.v1.Authorizer.FlushDecisionCache:input_type -> topaz.authz.v1.FlushDecisionCacheRequest
+	1,  // 32: topaz.authz.v1.Authorizer.IsWithCompanions:output_type -> topaz.authz.v1.IsWithCompanionsResponse
+	4,  // 33: topaz.authz.v1.Authorizer.IsBatch:output_type -> topaz.authz.v1.IsBatchResponse
+	6,  // 34: topaz.authz.v1.Authorizer.Watch:output_type -> topaz.authz.v1.WatchResponse
+	7,  // 35: topaz.authz.v1.Authorizer.Explain:output_type -> topaz.authz.v1.ExplainResponse
+	12, // 36: topaz.authz.v1.Authorizer.SearchDecisions:output_type -> topaz.authz.v1.SearchDecisionsResponse
+	14, // 37: topaz.authz.v1.Authorizer.FlushDecisionCache:output_type -> topaz.authz.v1.FlushDecisionCacheResponse
+	32, // [32:38] is the sub-list for method output_type
+	26, // [26:32] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
+}
+
+func init() { file_api_authz_v1_authorizer_proto_init() }
+func file_api_authz_v1_authorizer_proto_init() {
+	if File_api_authz_v1_authorizer_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_api_authz_v1_authorizer_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsWithCompanionsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsBatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsBatchItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsBatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*IsBatchResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExplainResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DecisionExplanation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RuleExplanation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BuiltinCall); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchDecisionsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchDecisionsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlushDecisionCacheRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_authz_v1_authorizer_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FlushDecisionCacheResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_api_authz_v1_authorizer_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_api_authz_v1_authorizer_proto_msgTypes[10].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_api_authz_v1_authorizer_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_authz_v1_authorizer_proto_goTypes,
+		DependencyIndexes: file_api_authz_v1_authorizer_proto_depIdxs,
+		EnumInfos:         file_api_authz_v1_authorizer_proto_enumTypes,
+		MessageInfos:      file_api_authz_v1_authorizer_proto_msgTypes,
+	}.Build()
+	File_api_authz_v1_authorizer_proto = out.File
+	file_api_authz_v1_authorizer_proto_rawDesc = nil
+	file_api_authz_v1_authorizer_proto_goTypes = nil
+	file_api_authz_v1_authorizer_proto_depIdxs = nil
+}
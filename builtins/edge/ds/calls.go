@@ -0,0 +1,158 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/pkg/errors"
+)
+
+type (
+	callsKey      struct{}
+	callLimitKey  struct{}
+	invocationKey struct{}
+)
+
+// ErrCallLimit is the error of the ds builtin call that exceeds the directory call limit of its evaluation.
+var ErrCallLimit = errors.New("directory call limit exceeded")
+
+// Calls counts the directory calls made by the ds builtins during an evaluation and,
+// when recording, keeps the arguments, result and latency of each of them.
+type Calls struct {
+	count  atomic.Int64
+	record bool
+
+	mu      sync.Mutex
+	records []*Call
+}
+
+// Call is the record of a ds builtin invocation that called the directory.
+type Call struct {
+	Builtin  string        `json:"builtin"`
+	Args     interface{}   `json:"args"`
+	Result   interface{}   `json:"result,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// WithCalls returns a context that counts the directory calls of the evaluations it is
+// passed to. When record is set, the ds builtins registered through Recorded also record
+// each of their invocations.
+func WithCalls(ctx context.Context, record bool) (context.Context, *Calls) {
+	calls := &Calls{record: record}
+	return context.WithValue(ctx, callsKey{}, calls), calls
+}
+
+// Count returns the number of directory calls made so far.
+func (c *Calls) Count() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.count.Load()
+}
+
+// Records returns the recorded invocations, in the order they were made.
+func (c *Calls) Records() []*Call {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Call{}, c.records...)
+}
+
+func (c *Calls) add(call *Call) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.records = append(c.records, call)
+}
+
+// invocation is the marker of a recorded ds builtin invocation, set when the invocation calls
+// the directory. Invocations of concurrent evaluations sharing the same Calls are told apart.
+type invocation struct {
+	called bool
+}
+
+// CallLimit counts the directory calls of an evaluation against its limit.
+type CallLimit struct {
+	max   int64
+	count atomic.Int64
+}
+
+// WithCallLimit returns a context that limits the evaluations it is passed to to max directory
+// calls, or ctx and a nil limit when max is not positive. The ds builtin call exceeding the limit
+// halts the evaluation with ErrCallLimit.
+func WithCallLimit(ctx context.Context, max int) (context.Context, *CallLimit) {
+	if max <= 0 {
+		return ctx, nil
+	}
+	limit := &CallLimit{max: int64(max)}
+	return context.WithValue(ctx, callLimitKey{}, limit), limit
+}
+
+// Exceeded reports whether a ds builtin call exceeded the limit. The error the evaluation
+// returns then only holds the message of ErrCallLimit.
+func (l *CallLimit) Exceeded() bool {
+	if l == nil {
+		return false
+	}
+	return l.count.Load() > l.max
+}
+
+// recordCall counts a directory call about to be made by a builtin, and returns the error
+// halting the evaluation when the call exceeds the limit of the evaluation.
+func recordCall(ctx context.Context) error {
+	if limit, ok := ctx.Value(callLimitKey{}).(*CallLimit); ok && limit.count.Add(1) > limit.max {
+		return rego.NewHaltError(ErrCallLimit)
+	}
+
+	if calls, ok := ctx.Value(callsKey{}).(*Calls); ok {
+		calls.count.Add(1)
+	}
+
+	if inv, ok := ctx.Value(invocationKey{}).(*invocation); ok {
+		inv.called = true
+	}
+
+	return nil
+}
+
+// Recorded wraps a ds builtin so that its invocations are recorded in the Calls of the
+// evaluation context, when recording. Help invocations, which make no directory call,
+// are not recorded.
+func Recorded(fn *rego.Function, impl rego.Builtin1) (*rego.Function, rego.Builtin1) {
+	return fn, func(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+		calls, ok := bctx.Context.Value(callsKey{}).(*Calls)
+		if !ok || !calls.record {
+			return impl(bctx, op1)
+		}
+
+		// the evaluations sharing calls may run concurrently, each invocation is marked on its own.
+		inv := &invocation{}
+		bctx.Context = context.WithValue(bctx.Context, invocationKey{}, inv)
+		start := time.Now()
+
+		result, err := impl(bctx, op1)
+
+		if !inv.called {
+			return result, err
+		}
+
+		call := &Call{Builtin: fn.Name, Duration: time.Since(start)}
+		call.Args, _ = ast.JSON(op1.Value)
+		if result != nil {
+			call.Result, _ = ast.JSON(result.Value)
+		}
+		if err != nil {
+			call.Error = err.Error()
+		}
+		calls.add(call)
+
+		return result, err
+	}
+}
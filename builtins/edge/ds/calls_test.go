@@ -0,0 +1,156 @@
+package ds
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCheck calls the directory unless it is asked for help, and fails for the "fail" key.
+func fakeCheck(bctx rego.BuiltinContext, op1 *ast.Term) (*ast.Term, error) {
+	var a struct {
+		Key string `json:"key"`
+	}
+	if err := ast.As(op1.Value, &a); err != nil {
+		return nil, err
+	}
+
+	if a.Key == "" {
+		return help("ds.fake", a)
+	}
+
+	if err := recordCall(bctx.Context); err != nil {
+		return nil, err
+	}
+
+	if a.Key == "fail" {
+		return nil, errors.New("directory unavailable")
+	}
+	return ast.BooleanTerm(true), nil
+}
+
+func call(ctx context.Context, impl rego.Builtin1, args string) {
+	_, _ = impl(rego.BuiltinContext{Context: ctx}, ast.MustParseTerm(args))
+}
+
+func TestRecorded(t *testing.T) {
+	_, impl := Recorded(&rego.Function{Name: "ds.fake"}, fakeCheck)
+
+	ctx, calls := WithCalls(context.Background(), true)
+	call(ctx, impl, `{"key": "alice"}`)
+	call(ctx, impl, `{}`)
+	call(ctx, impl, `{"key": "fail"}`)
+
+	assert.Equal(t, int64(2), calls.Count())
+
+	records := calls.Records()
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "ds.fake", records[0].Builtin)
+	assert.Equal(t, map[string]interface{}{"key": "alice"}, records[0].Args)
+	assert.Equal(t, true, records[0].Result)
+	assert.Empty(t, records[0].Error)
+
+	assert.Nil(t, records[1].Result)
+	assert.Equal(t, "directory unavailable", records[1].Error)
+}
+
+func TestRecordedConcurrent(t *testing.T) {
+	_, impl := Recorded(&rego.Function{Name: "ds.fake"}, fakeCheck)
+
+	ctx, calls := WithCalls(context.Background(), true)
+
+	// help invocations running alongside directory calls are not recorded.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			call(ctx, impl, `{"key": "alice"}`)
+		}()
+		go func() {
+			defer wg.Done()
+			call(ctx, impl, `{}`)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(50), calls.Count())
+
+	records := calls.Records()
+	require.Len(t, records, 50)
+	for _, record := range records {
+		assert.Equal(t, map[string]interface{}{"key": "alice"}, record.Args)
+	}
+}
+
+func TestRecordedDisabled(t *testing.T) {
+	_, impl := Recorded(&rego.Function{Name: "ds.fake"}, fakeCheck)
+
+	ctx, calls := WithCalls(context.Background(), false)
+	call(ctx, impl, `{"key": "alice"}`)
+
+	assert.Equal(t, int64(1), calls.Count())
+	assert.Empty(t, calls.Records())
+
+	// without Calls in the context the builtin still works.
+	call(context.Background(), impl, `{"key": "alice"}`)
+}
+
+func TestWithCallLimit(t *testing.T) {
+	_, impl := Recorded(&rego.Function{Name: "ds.fake"}, fakeCheck)
+
+	ctx, calls := WithCalls(context.Background(), true)
+	ctx, limit := WithCallLimit(ctx, 2)
+
+	for i := 0; i < 2; i++ {
+		_, err := impl(rego.BuiltinContext{Context: ctx}, ast.MustParseTerm(`{"key": "alice"}`))
+		require.NoError(t, err)
+	}
+
+	// help invocations make no directory call.
+	call(ctx, impl, `{}`)
+	assert.False(t, limit.Exceeded())
+
+	_, err := impl(rego.BuiltinContext{Context: ctx}, ast.MustParseTerm(`{"key": "alice"}`))
+	var halt *rego.HaltError
+	assert.ErrorAs(t, err, &halt)
+	assert.EqualError(t, err, ErrCallLimit.Error())
+	assert.True(t, limit.Exceeded())
+
+	// the call exceeding the limit is neither made nor recorded.
+	assert.Equal(t, int64(2), calls.Count())
+	assert.Len(t, calls.Records(), 2)
+
+	// a limit that is not positive is no limit.
+	noLimitCtx, noLimit := WithCallLimit(context.Background(), 0)
+	assert.Equal(t, context.Background(), noLimitCtx)
+	assert.Nil(t, noLimit)
+	assert.False(t, noLimit.Exceeded())
+}
+
+func TestWithCallLimitHaltsEvaluation(t *testing.T) {
+	fn, impl := Recorded(&rego.Function{
+		Name: "ds.fake",
+		Decl: types.NewFunction(types.Args(types.A), types.B),
+	}, fakeCheck)
+
+	ctx, limit := WithCallLimit(context.Background(), 1)
+
+	_, err := rego.New(
+		rego.Query(`ds.fake({"key": "alice"}); ds.fake({"key": "bob"})`),
+		rego.Function1(fn, impl),
+	).Eval(ctx)
+
+	// the builtin error halts the evaluation instead of making the call undefined.
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrCallLimit.Error())
+	assert.True(t, limit.Exceeded())
+}
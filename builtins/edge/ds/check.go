@@ -69,6 +69,10 @@ func RegisterCheckRelation(logger *zerolog.Logger, fnName string, dr resolvers.D
 				return nil, errors.Wrapf(err, "get directory client")
 			}
 
+			if err := recordCall(bctx.Context); err != nil {
+				return nil, err
+			}
+
 			resp, err := client.CheckRelation(bctx.Context, &dsr.CheckRelationRequest{
 				Subject:  a.Subject,
 				Relation: a.RelationType,
@@ -141,6 +145,10 @@ func RegisterCheckPermission(logger *zerolog.Logger, fnName string, dr resolvers
 				return nil, errors.Wrapf(err, "get directory client")
 			}
 
+			if err := recordCall(bctx.Context); err != nil {
+				return nil, err
+			}
+
 			resp, err := client.CheckPermission(bctx.Context, &dsr.CheckPermissionRequest{
 				Subject: a.Subject,
 
@@ -79,6 +79,10 @@ func RegisterGraph(logger *zerolog.Logger, fnName string, dr resolvers.Directory
 				return nil, errors.Wrapf(err, "get directory client")
 			}
 
+			if err := recordCall(bctx.Context); err != nil {
+				return nil, err
+			}
+
 			resp, err := client.GetGraph(bctx.Context, &dsr.GetGraphRequest{
 				Anchor:   a.Anchor,
 				Subject:  a.Subject,
@@ -45,6 +45,10 @@ func RegisterIdentity(logger *zerolog.Logger, fnName string, dr resolvers.Direct
 				return nil, errors.Wrapf(err, "get directory client")
 			}
 
+			if err := recordCall(bctx.Context); err != nil {
+				return nil, err
+			}
+
 			user, err := directory.GetIdentityV2(client, bctx.Context, a.Key)
 			switch {
 			case errors.Is(err, aerr.ErrDirectoryObjectNotFound):
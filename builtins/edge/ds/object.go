@@ -48,6 +48,10 @@ func RegisterObject(logger *zerolog.Logger, fnName string, dr resolvers.Director
 				return nil, errors.Wrapf(err, "get directory client")
 			}
 
+			if err := recordCall(bctx.Context); err != nil {
+				return nil, err
+			}
+
 			resp, err := client.GetObject(bctx.Context, &dsr.GetObjectRequest{
 				Param: a,
 			})
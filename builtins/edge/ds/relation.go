@@ -75,6 +75,10 @@ func RegisterRelation(logger *zerolog.Logger, fnName string, dr resolvers.Direct
 				return nil, errors.Wrapf(err, "get directory client")
 			}
 
+			if err := recordCall(bctx.Context); err != nil {
+				return nil, err
+			}
+
 			resp, err := client.GetRelation(bctx.Context, &reader.GetRelationRequest{Param: a.RelationIdentifier, WithObjects: &a.WithObjects})
 			if err != nil {
 				traceError(&bctx, fnName, err)
@@ -46,6 +46,10 @@ func RegisterUser(logger *zerolog.Logger, fnName string, dr resolvers.DirectoryR
 				return nil, errors.Wrapf(err, "get directory client")
 			}
 
+			if err := recordCall(bctx.Context); err != nil {
+				return nil, err
+			}
+
 			resp, err := client.GetObject(bctx.Context, &dsr.GetObjectRequest{
 				Param: &dsc.ObjectIdentifier{
 					Type: proto.String("user"),
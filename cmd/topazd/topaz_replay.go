@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/logger/file"
+	"github.com/aserto-dev/topaz/decision_log/logger/nop"
+	decisionlog_plugin "github.com/aserto-dev/topaz/decision_log/plugin"
+	"github.com/aserto-dev/topaz/decision_log/replay"
+	"github.com/aserto-dev/topaz/pkg/app/impl"
+	"github.com/aserto-dev/topaz/pkg/app/topaz"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var (
+	flagReplayConfigFile   string
+	flagReplayBundles      []string
+	flagReplayImage        string
+	flagReplayDecisions    []string
+	flagReplayJSON         bool
+	flagReplayFailOnChange bool
+)
+
+var cmdReplay = &cobra.Command{
+	Use:   "replay [args]",
+	Short: "Replay logged decisions against a candidate policy",
+	Long: `Evaluate the decisions recorded by the file decision logger against a candidate policy,
+loaded from local bundle paths or a local policy image, and report the decisions whose outcome changes.
+The directory configured in the configuration file is used to resolve identities.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(flagReplayBundles) == 0 && flagReplayImage == "" {
+			return errors.New("either --bundle or --image must be set")
+		}
+
+		configPath := config.Path(flagReplayConfigFile)
+		app, cleanup, err := topaz.BuildApp(os.Stderr, os.Stderr, configPath, func(cfg *config.Config) {
+			cfg.Command.Mode = config.CommandModeRun
+
+			cfg.OPA.LocalBundles.Paths = flagReplayBundles
+			cfg.OPA.LocalBundles.LocalPolicyImage = flagReplayImage
+			cfg.OPA.LocalBundles.Watch = false
+
+			// only the candidate policy is loaded, and replayed decisions are not logged.
+			cfg.OPA.Config.Bundles = nil
+			cfg.OPA.Config.Discovery = nil
+			delete(cfg.OPA.Config.Plugins, decisionlog_plugin.PluginName)
+		})
+		defer func() {
+			if cleanup != nil {
+				cleanup()
+			}
+		}()
+		if err != nil {
+			return err
+		}
+
+		decisionLogger, err := nop.New(app.Context, app.Logger)
+		if err != nil {
+			return err
+		}
+
+		// the authorizer server is created first, its caches register the compiler triggers of the runtime.
+		authorizerServer, err := impl.NewAuthorizerServer(app.Logger, &app.Configuration.Common, app.Resolver)
+		if err != nil {
+			return err
+		}
+
+		directory := topaz.DirectoryResolver(app.Context, app.Logger, app.Configuration)
+		runtime, cleanupRuntime, err := topaz.NewRuntimeResolver(app.Context, app.Logger, app.Configuration, decisionLogger, directory, app.Resolver.GetRuntimeHooks())
+		if cleanupRuntime != nil {
+			defer cleanupRuntime()
+		}
+		if err != nil {
+			return err
+		}
+		app.Resolver.SetRuntimeResolver(runtime)
+		app.Resolver.SetDirectoryResolver(directory)
+
+		r := replay.New(authorizerServer)
+
+		for _, path := range flagReplayDecisions {
+			if err := file.ReadLogSet(path, func(d *api.Decision) {
+				r.Replay(app.Context, d)
+			}); err != nil {
+				return err
+			}
+		}
+
+		report := r.Report()
+		if err := printReplayReport(os.Stdout, report, flagReplayJSON); err != nil {
+			return err
+		}
+
+		if flagReplayFailOnChange && len(report.Changes) > 0 {
+			return errors.Errorf("%d decisions changed", len(report.Changes))
+		}
+
+		return nil
+	},
+}
+
+func printReplayReport(w io.Writer, report *replay.Report, asJSON bool) error {
+	for _, c := range report.Changes {
+		if asJSON {
+			d, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(c.Decision)
+			if err != nil {
+				return err
+			}
+
+			b, err := json.Marshal(map[string]interface{}{
+				"decision":          json.RawMessage(d),
+				"replayed_outcomes": c.Outcomes,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(w, string(b))
+			continue
+		}
+
+		fmt.Fprintf(w, "%s  %s  %-5s -> %-5s  %s  %s  %s\n",
+			c.Decision.GetTimestamp().AsTime().Format(time.RFC3339),
+			c.Decision.Id,
+			strings.ToUpper(c.From()),
+			strings.ToUpper(c.To()),
+			c.Decision.GetUser().GetContext().GetIdentity(),
+			c.Decision.Path,
+			outcomeDiff(c.Decision.Outcomes, c.Outcomes),
+		)
+	}
+
+	if asJSON {
+		return nil
+	}
+
+	for _, f := range report.Failures {
+		fmt.Fprintf(w, "failed to replay decision %s: %v\n", f.Decision.Id, f.Err)
+	}
+
+	fmt.Fprintf(w, "replayed %d of %d decisions (%d skipped, %d failed): %d changed, %d allow -> deny, %d deny -> allow\n",
+		report.Replayed, report.Total, report.Skipped, len(report.Failures), len(report.Changes),
+		report.Count(decisionlog.OutcomeAllow, decisionlog.OutcomeDeny),
+		report.Count(decisionlog.OutcomeDeny, decisionlog.OutcomeAllow),
+	)
+
+	return nil
+}
+
+// outcomeDiff lists the outcomes that changed, e.g. allowed=true->false.
+func outcomeDiff(recorded, replayed map[string]bool) string {
+	var diff []string
+	for k, v := range replayed {
+		if r, ok := recorded[k]; !ok || r != v {
+			diff = append(diff, fmt.Sprintf("%s=%s->%t", k, recordedValue(recorded, k), v))
+		}
+	}
+	sort.Strings(diff)
+
+	return strings.Join(diff, " ")
+}
+
+func recordedValue(recorded map[string]bool, k string) string {
+	v, ok := recorded[k]
+	if !ok {
+		return "unset"
+	}
+	return fmt.Sprint(v)
+}
+
+// nolint: gochecknoinits
+func init() {
+	cmdReplay.Flags().StringVarP(
+		&flagReplayConfigFile,
+		"config-file", "c", "",
+		"set path of configuration file")
+	cmdReplay.Flags().StringSliceVarP(
+		&flagReplayBundles,
+		"bundle", "b", []string{},
+		"load paths as bundle files or root directories of the candidate policy (can be specified more than once)")
+	cmdReplay.Flags().StringVarP(
+		&flagReplayImage,
+		"image", "", "",
+		"local policy image of the candidate policy")
+	cmdReplay.Flags().StringSliceVarP(
+		&flagReplayDecisions,
+		"decisions", "d", []string{},
+		"decision log file to replay, its rotated files are replayed along with it (can be specified more than once)")
+	cmdReplay.Flags().BoolVarP(
+		&flagReplayJSON,
+		"json", "", false,
+		"print the changed decisions as JSON, one per line")
+	cmdReplay.Flags().BoolVarP(
+		&flagReplayFailOnChange,
+		"fail-on-change", "", false,
+		"exit with an error when any decision changed")
+	_ = cmdReplay.MarkFlagRequired("decisions")
+	rootCmd.AddCommand(cmdReplay)
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"os"
 
-	"github.com/aserto-dev/topaz/decision_log/logger/file"
 	"github.com/aserto-dev/topaz/pkg/app/auth"
 	"github.com/aserto-dev/topaz/pkg/app/topaz"
 	"github.com/aserto-dev/topaz/pkg/cc/config"
@@ -48,11 +47,17 @@ var cmdRun = &cobra.Command{
 			return err
 		}
 		directory := topaz.DirectoryResolver(app.Context, app.Logger, app.Configuration)
-		decisionlog, err := file.New(app.Context, &app.Configuration.DecisionLogger, app.Logger)
+		decisionlog, err := topaz.NewDecisionLogger(app.Context, app.Logger, app.Configuration)
 		if err != nil {
 			return err
 		}
-		runtime, _, err := topaz.NewRuntimeResolver(app.Context, app.Logger, app.Configuration, decisionlog, directory)
+		defer decisionlog.Shutdown()
+
+		runtime, cleanupRuntime, err := topaz.NewRuntimeResolver(app.Context, app.Logger, app.Configuration, decisionlog, directory, app.Resolver.GetRuntimeHooks())
+		if cleanupRuntime != nil {
+			// stops the plugins, which write their last decisions, before the decision logger shuts down.
+			defer cleanupRuntime()
+		}
 		if err != nil {
 			return err
 		}
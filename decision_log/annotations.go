@@ -0,0 +1,55 @@
+package decisionlog
+
+// Annotation keys set on decision records by the authorizer.
+const (
+	// AnnotationAPI holds the authorizer call that produced the decision (is, decision_tree, query or compile).
+	AnnotationAPI = "api"
+	// AnnotationQuery holds the rego query of query and compile calls.
+	AnnotationQuery = "query"
+	// AnnotationResult holds the JSON encoded result of query and compile calls.
+	AnnotationResult = "result"
+	// AnnotationEvalDuration holds the evaluation time of the decision, in nanoseconds.
+	AnnotationEvalDuration = "eval.duration_ns"
+	// AnnotationEvalDSCalls holds the number of directory calls made by ds builtins during the evaluation.
+	AnnotationEvalDSCalls = "eval.ds_calls"
+	// AnnotationEvalDSTrace holds the JSON encoded records of the ds builtin calls made during
+	// the evaluation, with their arguments, result and latency, when recording is enabled.
+	AnnotationEvalDSTrace = "eval.ds_trace"
+	// AnnotationEvalCached is set to true on decisions answered from the decision cache, which
+	// carry no evaluation time nor directory calls.
+	AnnotationEvalCached = "eval.cached"
+	// AnnotationEvalErrors holds the JSON encoded errors of the packages of a decision tree that
+	// failed to evaluate, by package name.
+	AnnotationEvalErrors = "eval.errors"
+	// AnnotationDecisionReasons holds the JSON encoded reasons the policy supplied for the decisions,
+	// from its <decision>_reasons rules, by decision.
+	AnnotationDecisionReasons = "decision.reasons"
+	// AnnotationDecisionObligations holds the JSON encoded value of the obligations rule of the policy.
+	AnnotationDecisionObligations = "decision.obligations"
+	// AnnotationBundleRevision holds the revision of the active policy bundle. When several
+	// bundles are active it holds their name=revision pairs, sorted by name and comma separated.
+	AnnotationBundleRevision = "bundle.revision"
+	// AnnotationVersion holds the version of topaz that made the decision.
+	AnnotationVersion = "topaz.version"
+)
+
+// Annotation keys of hash chained decision records.
+const (
+	// AnnotationChainID identifies the chain, a new chain starts every time the decision logger starts.
+	AnnotationChainID = "chain.id"
+	// AnnotationChainSeq holds the position of the record in its chain, starting at 0.
+	AnnotationChainSeq = "chain.seq"
+	// AnnotationChainPrev holds the hash of the previous record in the chain.
+	AnnotationChainPrev = "chain.prev"
+	// AnnotationChainLinkID holds, on the first record of a chain, the id of the chain it continues.
+	AnnotationChainLinkID = "chain.link_id"
+	// AnnotationChainLinkSeq holds, on the first record of a chain, the position of the last
+	// checkpoint of the chain it continues. The previous hash of the record is the hash of that checkpoint.
+	AnnotationChainLinkSeq = "chain.link_seq"
+	// AnnotationChainHash holds the hash of the record.
+	AnnotationChainHash = "chain.hash"
+	// AnnotationCheckpoint holds the signature of a checkpoint record.
+	AnnotationCheckpoint = "chain.checkpoint"
+	// AnnotationCheckpointKey identifies the key that signed a checkpoint record.
+	AnnotationCheckpointKey = "chain.key_id"
+)
@@ -0,0 +1,342 @@
+package decisionlog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+)
+
+// OverflowPolicy decides what happens to a decision that is logged while the async queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowDropOldest evicts the oldest queued decision to make room for the new one.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowDropNewest discards the decision being logged.
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+	// OverflowBlock blocks the caller until there is room in the queue, at most for the block
+	// timeout, after which the decision being logged is discarded.
+	OverflowBlock OverflowPolicy = "block"
+)
+
+var ErrLoggerClosed = errors.New("decision logger is shut down")
+
+var (
+	asyncQueued = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "topaz",
+		Subsystem: "decision_log",
+		Name:      "queued_total",
+		Help:      "Number of decisions accepted into the async decision log queue.",
+	}, []string{"sink"})
+	asyncDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "topaz",
+		Subsystem: "decision_log",
+		Name:      "dropped_total",
+		Help:      "Number of decisions dropped because the async decision log queue was full.",
+	}, []string{"sink", "policy"})
+	asyncWritten = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "topaz",
+		Subsystem: "decision_log",
+		Name:      "written_total",
+		Help:      "Number of decisions written to the sink by the async decision logger.",
+	}, []string{"sink"})
+	asyncFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "topaz",
+		Subsystem: "decision_log",
+		Name:      "failed_total",
+		Help:      "Number of decisions the sink failed to write.",
+	}, []string{"sink"})
+)
+
+// AsyncConfig configures the asynchronous batching wrapper around a decision logger.
+type AsyncConfig struct {
+	Enabled        bool           `json:"enabled"`
+	QueueSize      int            `json:"queue_size"`
+	BatchSize      int            `json:"batch_size"`
+	FlushInterval  time.Duration  `json:"flush_interval"`
+	OverflowPolicy OverflowPolicy `json:"overflow_policy"`
+	BlockTimeout   time.Duration  `json:"block_timeout"`
+}
+
+func (cfg *AsyncConfig) SetDefaults() {
+	if cfg.QueueSize == 0 {
+		cfg.QueueSize = 10000
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.OverflowPolicy == "" {
+		cfg.OverflowPolicy = OverflowDropOldest
+	}
+	if cfg.BlockTimeout == 0 {
+		cfg.BlockTimeout = time.Second
+	}
+}
+
+func (cfg *AsyncConfig) Validate() error {
+	switch cfg.OverflowPolicy {
+	case OverflowDropOldest, OverflowDropNewest, OverflowBlock:
+	default:
+		return errors.Errorf("unknown overflow policy [%s]", cfg.OverflowPolicy)
+	}
+
+	if cfg.QueueSize < 0 || cfg.BatchSize < 0 || cfg.FlushInterval < 0 || cfg.BlockTimeout < 0 {
+		return errors.New("queue_size, batch_size, flush_interval and block_timeout must be positive")
+	}
+
+	if cfg.BatchSize > cfg.QueueSize {
+		return errors.New("batch_size must not be larger than queue_size")
+	}
+
+	return nil
+}
+
+// AsyncStats holds the counters of an AsyncLogger.
+type AsyncStats struct {
+	Queued  uint64
+	Dropped uint64
+	Written uint64
+	Failed  uint64
+}
+
+// AsyncLogger queues decisions in memory and writes them to the wrapped decision logger
+// in batches from a background goroutine, so logging never waits on the sink.
+type AsyncLogger struct {
+	cfg    *AsyncConfig
+	name   string
+	sink   DecisionLogger
+	logger *zerolog.Logger
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	queue   []*api.Decision
+	closed  bool
+
+	flush chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+
+	queued  uint64
+	dropped uint64
+	written uint64
+	failed  uint64
+}
+
+var _ QueuingDecisionLogger = (*AsyncLogger)(nil)
+
+// NewAsync wraps sink in an AsyncLogger. The name is used to label metrics and log messages.
+func NewAsync(sink DecisionLogger, cfg *AsyncConfig, name string, logger *zerolog.Logger) (*AsyncLogger, error) {
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	newLogger := logger.With().Str("component", "decision-log-async").Str("sink", name).Logger()
+
+	l := &AsyncLogger{
+		cfg:    cfg,
+		name:   name,
+		sink:   sink,
+		logger: &newLogger,
+		queue:  make([]*api.Decision, 0, cfg.BatchSize),
+		flush:  make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	l.notFull = sync.NewCond(&l.mu)
+
+	go l.run()
+
+	return l, nil
+}
+
+// Log enqueues the decision. Depending on the overflow policy a full queue either drops
+// a decision or blocks until the background writer catches up or the block timeout expires.
+func (l *AsyncLogger) Log(d *api.Decision) error {
+	var deadline time.Time
+
+	l.mu.Lock()
+
+	for len(l.queue) >= l.cfg.QueueSize && !l.closed {
+		switch l.cfg.OverflowPolicy {
+		case OverflowDropNewest:
+			l.mu.Unlock()
+			l.drop()
+			return nil
+		case OverflowDropOldest:
+			l.queue[0] = nil
+			l.queue = l.queue[1:]
+			l.drop()
+		case OverflowBlock:
+			if deadline.IsZero() {
+				deadline = time.Now().Add(l.cfg.BlockTimeout)
+				timer := time.AfterFunc(l.cfg.BlockTimeout, l.wakeBlocked)
+				defer timer.Stop()
+			} else if !time.Now().Before(deadline) {
+				l.mu.Unlock()
+				l.drop()
+				return nil
+			}
+			l.notFull.Wait()
+		}
+	}
+
+	if l.closed {
+		l.mu.Unlock()
+		return ErrLoggerClosed
+	}
+
+	l.queue = append(l.queue, d)
+	size := len(l.queue)
+	l.mu.Unlock()
+
+	atomic.AddUint64(&l.queued, 1)
+	asyncQueued.WithLabelValues(l.name).Inc()
+
+	if size >= l.cfg.BatchSize {
+		select {
+		case l.flush <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops accepting decisions, writes everything still queued and shuts down the wrapped logger.
+func (l *AsyncLogger) Shutdown() {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+	l.closed = true
+	l.notFull.Broadcast()
+	l.mu.Unlock()
+
+	close(l.stop)
+	<-l.done
+
+	l.sink.Shutdown()
+}
+
+// Queuing reports that decisions are written from the queue.
+func (l *AsyncLogger) Queuing() bool {
+	return true
+}
+
+// Unwrap returns the wrapped decision logger.
+func (l *AsyncLogger) Unwrap() DecisionLogger {
+	return l.sink
+}
+
+// Stats returns a snapshot of the logger counters.
+func (l *AsyncLogger) Stats() AsyncStats {
+	return AsyncStats{
+		Queued:  atomic.LoadUint64(&l.queued),
+		Dropped: atomic.LoadUint64(&l.dropped),
+		Written: atomic.LoadUint64(&l.written),
+		Failed:  atomic.LoadUint64(&l.failed),
+	}
+}
+
+// wakeBlocked wakes the callers blocked on a full queue, so that they can check their deadline.
+func (l *AsyncLogger) wakeBlocked() {
+	l.mu.Lock()
+	l.notFull.Broadcast()
+	l.mu.Unlock()
+}
+
+func (l *AsyncLogger) drop() {
+	atomic.AddUint64(&l.dropped, 1)
+	asyncDropped.WithLabelValues(l.name, string(l.cfg.OverflowPolicy)).Inc()
+}
+
+func (l *AsyncLogger) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.flush:
+		case <-ticker.C:
+		case <-l.stop:
+			l.writeQueued()
+			return
+		}
+		l.writeQueued()
+	}
+}
+
+// writeQueued writes all queued decisions to the sink, at most BatchSize at a time.
+func (l *AsyncLogger) writeQueued() {
+	for {
+		batch := l.next()
+		if len(batch) == 0 {
+			return
+		}
+		l.write(batch)
+	}
+}
+
+func (l *AsyncLogger) next() []*api.Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := len(l.queue)
+	if n > l.cfg.BatchSize {
+		n = l.cfg.BatchSize
+	}
+
+	batch := make([]*api.Decision, n)
+	copy(batch, l.queue[:n])
+	for i := 0; i < n; i++ {
+		l.queue[i] = nil
+	}
+	l.queue = l.queue[n:]
+
+	l.notFull.Broadcast()
+
+	return batch
+}
+
+func (l *AsyncLogger) write(batch []*api.Decision) {
+	if bl, ok := l.sink.(BatchDecisionLogger); ok {
+		if err := bl.LogBatch(batch); err != nil {
+			l.failures(len(batch), err)
+			return
+		}
+		l.successes(len(batch))
+		return
+	}
+
+	for _, d := range batch {
+		if err := l.sink.Log(d); err != nil {
+			l.failures(1, err)
+			continue
+		}
+		l.successes(1)
+	}
+}
+
+func (l *AsyncLogger) successes(n int) {
+	atomic.AddUint64(&l.written, uint64(n))
+	asyncWritten.WithLabelValues(l.name).Add(float64(n))
+}
+
+func (l *AsyncLogger) failures(n int, err error) {
+	atomic.AddUint64(&l.failed, uint64(n))
+	asyncFailed.WithLabelValues(l.name).Add(float64(n))
+	l.logger.Error().Err(err).Int("count", n).Msg("failed to write decisions")
+}
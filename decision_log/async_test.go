@@ -0,0 +1,140 @@
+package decisionlog_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu      sync.Mutex
+	ids     []string
+	batches int
+
+	// when set, the first write signals entered and waits for release.
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (r *recorder) Log(d *api.Decision) error {
+	return r.LogBatch([]*api.Decision{d})
+}
+
+func (r *recorder) LogBatch(batch []*api.Decision) error {
+	if r.release != nil {
+		r.once.Do(func() {
+			close(r.entered)
+			<-r.release
+		})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.batches++
+	for _, d := range batch {
+		r.ids = append(r.ids, d.Id)
+	}
+	return nil
+}
+
+func (r *recorder) Shutdown() {}
+
+func (r *recorder) logged() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.ids...)
+}
+
+func TestAsyncShutdownDrainsQueue(t *testing.T) {
+	sink := &recorder{}
+	logger := zerolog.Nop()
+
+	async, err := decisionlog.NewAsync(sink, &decisionlog.AsyncConfig{
+		QueueSize:     100,
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+	}, "test", &logger)
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, async.Log(&api.Decision{Id: id}))
+	}
+
+	async.Shutdown()
+
+	assert.Equal(t, []string{"1", "2", "3"}, sink.logged())
+	assert.Equal(t, 1, sink.batches)
+	assert.Equal(t, decisionlog.ErrLoggerClosed, async.Log(&api.Decision{Id: "4"}))
+}
+
+func TestAsyncOverflow(t *testing.T) {
+	tests := []struct {
+		policy   decisionlog.OverflowPolicy
+		expected []string
+		queued   uint64
+	}{
+		{decisionlog.OverflowDropOldest, []string{"0", "3", "4"}, 5},
+		{decisionlog.OverflowDropNewest, []string{"0", "1", "2"}, 3},
+		// a caller blocked on the full queue gives up after the block timeout.
+		{decisionlog.OverflowBlock, []string{"0", "1", "2"}, 3},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			sink := &recorder{entered: make(chan struct{}), release: make(chan struct{})}
+			logger := zerolog.Nop()
+
+			async, err := decisionlog.NewAsync(sink, &decisionlog.AsyncConfig{
+				QueueSize:      2,
+				BatchSize:      1,
+				FlushInterval:  time.Hour,
+				OverflowPolicy: tc.policy,
+				BlockTimeout:   10 * time.Millisecond,
+			}, "test", &logger)
+			require.NoError(t, err)
+
+			// Park the background writer inside the sink so the queue fills up.
+			require.NoError(t, async.Log(&api.Decision{Id: "0"}))
+			<-sink.entered
+
+			for _, id := range []string{"1", "2", "3", "4"} {
+				require.NoError(t, async.Log(&api.Decision{Id: id}))
+			}
+
+			close(sink.release)
+			async.Shutdown()
+
+			assert.Equal(t, tc.expected, sink.logged())
+			assert.Equal(t, decisionlog.AsyncStats{Queued: tc.queued, Dropped: 2, Written: 3}, async.Stats())
+		})
+	}
+}
+
+func TestAsyncInvalidConfig(t *testing.T) {
+	logger := zerolog.Nop()
+
+	_, err := decisionlog.NewAsync(&recorder{}, &decisionlog.AsyncConfig{
+		QueueSize: 1,
+		BatchSize: 10,
+	}, "test", &logger)
+	assert.Error(t, err)
+
+	_, err = decisionlog.NewAsync(&recorder{}, &decisionlog.AsyncConfig{
+		OverflowPolicy: "drop-random",
+	}, "test", &logger)
+	assert.Error(t, err)
+
+	_, err = decisionlog.NewAsync(&recorder{}, &decisionlog.AsyncConfig{
+		OverflowPolicy: decisionlog.OverflowBlock,
+		BlockTimeout:   -time.Second,
+	}, "test", &logger)
+	assert.Error(t, err)
+}
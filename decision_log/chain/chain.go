@@ -0,0 +1,260 @@
+package chain
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultCheckpointEvery    = 1000
+	defaultCheckpointInterval = time.Minute
+)
+
+// Config of the hash chain of decision records.
+type Config struct {
+	Enabled bool `json:"enabled"`
+	// SigningKeyPath is the path of the PEM encoded PKCS #8 Ed25519 private key that signs checkpoints.
+	SigningKeyPath string `json:"signing_key_path"`
+	// StatePath is the path of the file keeping the last checkpoint, which the next chain links to.
+	StatePath string `json:"state_path"`
+	// CheckpointEvery is the number of records after which a checkpoint is written (default: 1000).
+	CheckpointEvery int `json:"checkpoint_every"`
+	// CheckpointInterval is the longest time between a record and the checkpoint covering it (default: 1m).
+	CheckpointInterval string `json:"checkpoint_interval"`
+
+	interval time.Duration
+	key      ed25519.PrivateKey
+}
+
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.SigningKeyPath == "" {
+		return errors.New("signing_key_path not set")
+	}
+
+	key, err := LoadPrivateKey(cfg.SigningKeyPath)
+	if err != nil {
+		return err
+	}
+	cfg.key = key
+
+	if cfg.StatePath == "" {
+		return errors.New("state_path not set")
+	}
+
+	if cfg.CheckpointEvery < 0 {
+		return errors.New("checkpoint_every must not be negative")
+	}
+	if cfg.CheckpointEvery == 0 {
+		cfg.CheckpointEvery = defaultCheckpointEvery
+	}
+
+	cfg.interval = defaultCheckpointInterval
+	if cfg.CheckpointInterval != "" {
+		interval, err := time.ParseDuration(cfg.CheckpointInterval)
+		if err != nil || interval <= 0 {
+			return errors.Errorf("invalid checkpoint_interval [%s]", cfg.CheckpointInterval)
+		}
+		cfg.interval = interval
+	}
+
+	return nil
+}
+
+// Interval returns the longest time between checkpoints.
+func (cfg *Config) Interval() time.Duration {
+	if cfg.interval == 0 {
+		return defaultCheckpointInterval
+	}
+	return cfg.interval
+}
+
+// Chain links decision records to each other. Every record carries its position in the
+// chain, the hash of the previous record and its own hash, so that removing, reordering
+// or changing records breaks the chain. Checkpoint records, signed with the configured
+// key, periodically vouch for the chain up to them.
+//
+// Every Chain starts a new chain with its own id, at position 0. Its first record links to
+// the last checkpoint of the previous chain, kept in the state file, so that chains cannot
+// be removed from the log either.
+type Chain struct {
+	mu        sync.Mutex
+	id        string
+	seq       uint64
+	head      string
+	link      *state
+	statePath string
+	key       ed25519.PrivateKey
+	keyID     string
+	every     int
+	pending   int
+}
+
+// New starts a new chain. The config must have been validated, which loads the signing key.
+func New(cfg *Config) (*Chain, error) {
+	link, err := loadState(cfg.StatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Chain{
+		id:        uuid.NewString(),
+		link:      link,
+		statePath: cfg.StatePath,
+		key:       cfg.key,
+		keyID:     KeyID(cfg.key.Public().(ed25519.PublicKey)),
+		every:     cfg.CheckpointEvery,
+	}
+	if link != nil {
+		c.head = link.Hash
+	}
+
+	return c, nil
+}
+
+// Append links d to the chain and hands it to log. The chain is locked until log returns,
+// so records reach the decision logger in chain order, log is expected to only queue them.
+// The chain only moves forward when log succeeds. A checkpoint follows every
+// checkpoint_every records.
+func (c *Chain) Append(d *api.Decision, log func(*api.Decision) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.append(d, log); err != nil {
+		return err
+	}
+
+	c.pending++
+	if c.pending >= c.every {
+		return c.checkpoint(log)
+	}
+
+	return nil
+}
+
+// Checkpoint writes a signed checkpoint, unless no record was appended since the last one.
+// The checkpoint is kept in the state file once written.
+func (c *Chain) Checkpoint(log func(*api.Decision) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending == 0 {
+		return nil
+	}
+
+	return c.checkpoint(log)
+}
+
+func (c *Chain) checkpoint(log func(*api.Decision) error) error {
+	d := &api.Decision{
+		Id:        uuid.NewString(),
+		Timestamp: timestamppb.Now(),
+		Annotations: map[string]string{
+			decisionlog.AnnotationCheckpointKey: c.keyID,
+			decisionlog.AnnotationCheckpoint:    base64.StdEncoding.EncodeToString(ed25519.Sign(c.key, checkpointMessage(c.id, c.seq, c.head))),
+		},
+	}
+
+	if err := c.append(d, log); err != nil {
+		return errors.Wrap(err, "failed to write decision log checkpoint")
+	}
+
+	c.pending = 0
+	return (&state{ChainID: c.id, Seq: c.seq - 1, Hash: c.head}).save(c.statePath)
+}
+
+func (c *Chain) append(d *api.Decision, log func(*api.Decision) error) error {
+	if d.Annotations == nil {
+		d.Annotations = map[string]string{}
+	}
+
+	d.Annotations[decisionlog.AnnotationChainID] = c.id
+	d.Annotations[decisionlog.AnnotationChainSeq] = strconv.FormatUint(c.seq, 10)
+	d.Annotations[decisionlog.AnnotationChainPrev] = c.head
+	if c.seq == 0 && c.link != nil {
+		d.Annotations[decisionlog.AnnotationChainLinkID] = c.link.ChainID
+		d.Annotations[decisionlog.AnnotationChainLinkSeq] = strconv.FormatUint(c.link.Seq, 10)
+	}
+
+	hash, err := Hash(d)
+	if err != nil {
+		return err
+	}
+	d.Annotations[decisionlog.AnnotationChainHash] = hash
+
+	if err := log(d); err != nil {
+		return err
+	}
+
+	c.seq++
+	c.head = hash
+
+	return nil
+}
+
+// hashVersion identifies the encoding hashes are computed on, it prefixes every hash.
+const hashVersion = "v1"
+
+// Hash returns the versioned SHA-256 of the canonical encoding of d, leaving out its own
+// hash annotation. The v1 encoding is the protojson encoding of the decision with proto
+// field names, written again with sorted object keys, numbers in their shortest form,
+// no insignificant whitespace and no HTML escaping, so it does not depend on the protobuf
+// runtime that wrote the record.
+func Hash(d *api.Decision) (string, error) {
+	c := proto.Clone(d).(*api.Decision)
+	delete(c.Annotations, decisionlog.AnnotationChainHash)
+
+	b, err := canonicalJSON(c)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode decision")
+	}
+
+	sum := sha256.Sum256(b)
+	return hashVersion + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+func canonicalJSON(d *api.Decision) ([]byte, error) {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// checkpointMessage is the message signed by the checkpoint at position seq of a chain,
+// which vouches for all the records before it through the hash of the previous record.
+func checkpointMessage(chainID string, seq uint64, prev string) []byte {
+	return []byte(fmt.Sprintf("topaz decision log checkpoint\n%s\n%d\n%s", chainID, seq, prev))
+}
@@ -0,0 +1,279 @@
+package chain_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/topaz/decision_log/chain"
+	"github.com/aserto-dev/topaz/decision_log/logger/file"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func writeKey(t *testing.T) (string, ed25519.PublicKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	return path, pub
+}
+
+func newConfig(t *testing.T, every int) (*chain.Config, ed25519.PublicKey) {
+	path, pub := writeKey(t)
+
+	cfg := &chain.Config{
+		Enabled:         true,
+		SigningKeyPath:  path,
+		StatePath:       filepath.Join(t.TempDir(), "chain.json"),
+		CheckpointEvery: every,
+	}
+	require.NoError(t, cfg.Validate())
+
+	return cfg, pub
+}
+
+func newChain(t *testing.T, every int) (*chain.Chain, ed25519.PublicKey) {
+	cfg, pub := newConfig(t, every)
+
+	c, err := chain.New(cfg)
+	require.NoError(t, err)
+
+	return c, pub
+}
+
+func decision(i int) *api.Decision {
+	resource, _ := structpb.NewStruct(map[string]interface{}{"id": float64(i), "owner": "alice@acmecorp.com"})
+
+	return &api.Decision{
+		Id:        strconv.Itoa(i),
+		Timestamp: timestamppb.Now(),
+		Path:      "peoplefinder.GET.users",
+		User:      &api.DecisionUser{Id: "alice", Email: "alice@acmecorp.com"},
+		Resource:  resource,
+		Outcomes:  map[string]bool{"allowed": i%2 == 0},
+	}
+}
+
+// write appends n decisions to a new chain and returns the records in the order they were logged.
+func write(t *testing.T, n, every int) ([]*api.Decision, ed25519.PublicKey) {
+	cfg, pub := newConfig(t, every)
+	return writeChain(t, cfg, n), pub
+}
+
+// writeChain appends n decisions to a new chain of cfg and ends it with a checkpoint.
+func writeChain(t *testing.T, cfg *chain.Config, n int) []*api.Decision {
+	c, err := chain.New(cfg)
+	require.NoError(t, err)
+
+	var records []*api.Decision
+	log := func(d *api.Decision) error {
+		records = append(records, d)
+		return nil
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, c.Append(decision(i), log))
+	}
+	require.NoError(t, c.Checkpoint(log))
+
+	return records
+}
+
+func verify(pub ed25519.PublicKey, records []*api.Decision) *chain.Report {
+	v := chain.NewVerifier(pub)
+	for _, d := range records {
+		v.Add(d)
+	}
+	return v.Report()
+}
+
+func kinds(r *chain.Report) []string {
+	result := []string{}
+	for _, i := range r.Issues {
+		result = append(result, i.Kind)
+	}
+	return result
+}
+
+func TestHashCanonical(t *testing.T) {
+	resource, err := structpb.NewStruct(map[string]interface{}{"owner": "<alice>", "id": 1.5})
+	require.NoError(t, err)
+
+	d := &api.Decision{
+		Id:          "1",
+		Timestamp:   timestamppb.New(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Path:        "peoplefinder.GET.users",
+		User:        &api.DecisionUser{Id: "alice"},
+		Resource:    resource,
+		Outcomes:    map[string]bool{"allowed": true},
+		Annotations: map[string]string{"chain.hash": "v1:0", "b": "2", "a": "1"},
+	}
+
+	canonical := `{"annotations":{"a":"1","b":"2"},"id":"1","outcomes":{"allowed":true},"path":"peoplefinder.GET.users",` +
+		`"resource":{"id":1.5,"owner":"<alice>"},"timestamp":"2023-01-02T03:04:05Z","user":{"id":"alice"}}`
+	sum := sha256.Sum256([]byte(canonical))
+
+	hash, err := chain.Hash(d)
+	require.NoError(t, err)
+	assert.Equal(t, "v1:"+hex.EncodeToString(sum[:]), hash)
+}
+
+func TestVerify(t *testing.T) {
+	records, pub := write(t, 10, 4)
+
+	// 10 decisions, a checkpoint after every 4 and a final one.
+	require.Len(t, records, 13)
+
+	report := verify(pub, records)
+	assert.True(t, report.OK(), report.Issues)
+	require.Len(t, report.Chains, 1)
+	assert.Equal(t, 13, report.Chains[0].Records)
+	assert.Equal(t, 3, report.Chains[0].Checkpoints)
+	assert.Equal(t, 0, report.Chains[0].Unsigned)
+}
+
+func TestVerifyTampering(t *testing.T) {
+	tests := map[string]struct {
+		tamper   func(records []*api.Decision) []*api.Decision
+		expected []string
+	}{
+		"changed record": {
+			func(records []*api.Decision) []*api.Decision {
+				records[1].Outcomes["allowed"] = true
+				return records
+			},
+			[]string{chain.IssueTampered},
+		},
+		"removed record": {
+			func(records []*api.Decision) []*api.Decision {
+				return append(records[:2], records[3:]...)
+			},
+			[]string{chain.IssueGap},
+		},
+		"swapped records": {
+			func(records []*api.Decision) []*api.Decision {
+				records[2], records[3] = records[3], records[2]
+				return records
+			},
+			[]string{chain.IssueGap, chain.IssueOutOfOrder},
+		},
+		"inserted record": {
+			func(records []*api.Decision) []*api.Decision {
+				return append(records[:2], append([]*api.Decision{decision(42)}, records[2:]...)...)
+			},
+			[]string{chain.IssueUnchained},
+		},
+		"rewritten chain": {
+			// a rewritten chain has consistent hashes, but the checkpoints cannot be signed again.
+			func(records []*api.Decision) []*api.Decision {
+				rewritten, _ := write(t, 10, 4)
+				return rewritten
+			},
+			[]string{chain.IssueBadSignature, chain.IssueBadSignature, chain.IssueBadSignature, chain.IssueUnsigned},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			records, pub := write(t, 10, 4)
+			assert.Equal(t, tc.expected, kinds(verify(pub, tc.tamper(records))))
+		})
+	}
+}
+
+func TestVerifyUnsigned(t *testing.T) {
+	c, pub := newChain(t, 100)
+
+	var records []*api.Decision
+	for i := 0; i < 3; i++ {
+		require.NoError(t, c.Append(decision(i), func(d *api.Decision) error {
+			records = append(records, d)
+			return nil
+		}))
+	}
+
+	report := verify(pub, records)
+	assert.False(t, report.OK())
+	assert.Equal(t, []string{chain.IssueUnsigned}, kinds(report))
+	assert.Equal(t, 3, report.Chains[0].Unsigned)
+}
+
+func TestVerifyLinkedChains(t *testing.T) {
+	cfg, pub := newConfig(t, 4)
+
+	// three chains, as written by three runs of topaz, and the state left by the first one.
+	chains := [][]*api.Decision{writeChain(t, cfg, 5)}
+	stale, err := os.ReadFile(cfg.StatePath)
+	require.NoError(t, err)
+	chains = append(chains, writeChain(t, cfg, 5), writeChain(t, cfg, 5))
+	join := func(chains ...[]*api.Decision) []*api.Decision {
+		var records []*api.Decision
+		for _, c := range chains {
+			records = append(records, c...)
+		}
+		return records
+	}
+
+	report := verify(pub, join(chains...))
+	assert.True(t, report.OK(), report.Issues)
+	require.Len(t, report.Chains, 3)
+	assert.Equal(t, "", report.Chains[0].Continues)
+	assert.Equal(t, report.Chains[0].ChainID, report.Chains[1].Continues)
+	assert.Equal(t, report.Chains[1].ChainID, report.Chains[2].Continues)
+
+	// the oldest records may have been removed by rotation.
+	assert.True(t, verify(pub, join(chains[1], chains[2])).OK())
+
+	assert.Equal(t, []string{chain.IssueGap}, kinds(verify(pub, join(chains[0], chains[2]))), "removed chain")
+
+	// a chain started from the state of an older chain forks the log.
+	require.NoError(t, os.WriteFile(cfg.StatePath, stale, 0o600))
+	forked := writeChain(t, cfg, 5)
+	assert.Equal(t, []string{chain.IssueBrokenLink}, kinds(verify(pub, join(chains[0], chains[1], forked))), "forked chain")
+
+	// a chain started without the state of the previous one does not continue it.
+	cfg.StatePath = filepath.Join(t.TempDir(), "chain.json")
+	assert.Equal(t, []string{chain.IssueBrokenLink}, kinds(verify(pub, join(chains[0], writeChain(t, cfg, 5)))), "unlinked chain")
+}
+
+func TestVerifyFileLogSet(t *testing.T) {
+	c, pub := newChain(t, 3)
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	logger := zerolog.Nop()
+
+	l, err := file.New(context.Background(), &file.Config{LogFilePath: path}, &logger)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, c.Append(decision(i), l.Log))
+	}
+	require.NoError(t, c.Checkpoint(l.Log))
+	l.Shutdown()
+
+	v := chain.NewVerifier(pub)
+	require.NoError(t, file.ReadLogSet(path, v.Add))
+
+	report := v.Report()
+	assert.True(t, report.OK(), report.Issues)
+	require.Len(t, report.Chains, 1)
+	assert.Equal(t, 7, report.Chains[0].Records)
+}
@@ -0,0 +1,79 @@
+package chain
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// LoadPrivateKey reads a PEM encoded PKCS #8 Ed25519 private key, as generated by
+// `openssl genpkey -algorithm ed25519`.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse private key [%s]", path)
+	}
+
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("private key [%s] is not an Ed25519 key", path)
+	}
+
+	return edKey, nil
+}
+
+// LoadPublicKey reads a PEM encoded PKIX Ed25519 public key, as generated by
+// `openssl pkey -pubout`.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse public key [%s]", path)
+	}
+
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("public key [%s] is not an Ed25519 key", path)
+	}
+
+	return edKey, nil
+}
+
+// KeyID returns the identifier of a public key recorded in checkpoints, the first
+// 8 bytes of its SHA-256 in hex.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+func readPEM(path, blockType string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read key file [%s]", path)
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, errors.Errorf("no %s block found in [%s]", blockType, path)
+		}
+		if block.Type == blockType {
+			return block, nil
+		}
+	}
+}
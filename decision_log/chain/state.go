@@ -0,0 +1,62 @@
+package chain
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// state is the last checkpoint written, which the first record of the next chain links to.
+type state struct {
+	ChainID string `json:"chain_id"`
+	Seq     uint64 `json:"seq"`
+	Hash    string `json:"hash"`
+}
+
+// loadState reads the state file, a missing file returns no state.
+func loadState(path string) (*state, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read hash chain state [%s]", path)
+	}
+
+	s := &state{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse hash chain state [%s]", path)
+	}
+
+	return s, nil
+}
+
+// save replaces the state file, so that it is never left partially written.
+func (s *state) save(path string) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode hash chain state")
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "failed to save hash chain state")
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to save hash chain state")
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to save hash chain state")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "failed to save hash chain state")
+	}
+
+	return errors.Wrap(os.Rename(f.Name(), path), "failed to save hash chain state")
+}
@@ -0,0 +1,272 @@
+package chain
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+)
+
+// Kinds of issues found when verifying decision records.
+const (
+	// IssueUnchained is a record without chain annotations.
+	IssueUnchained = "unchained"
+	// IssueMalformed is a record with invalid chain annotations.
+	IssueMalformed = "malformed"
+	// IssueTampered is a record whose content does not match its hash.
+	IssueTampered = "tampered"
+	// IssueGap is a range of missing records.
+	IssueGap = "gap"
+	// IssueOutOfOrder is a record repeated or found after records that follow it.
+	IssueOutOfOrder = "out-of-order"
+	// IssueBrokenLink is a record that does not point to the record before it.
+	IssueBrokenLink = "broken-link"
+	// IssueBadSignature is a checkpoint whose signature is not valid for the verification key.
+	IssueBadSignature = "bad-signature"
+	// IssueUnsigned is a chain that does not end with a valid checkpoint.
+	IssueUnsigned = "unsigned"
+)
+
+type Issue struct {
+	Kind       string
+	ChainID    string
+	Seq        uint64
+	DecisionID string
+	Message    string
+}
+
+func (i Issue) String() string {
+	if i.ChainID == "" {
+		return fmt.Sprintf("%s: decision %s: %s", i.Kind, i.DecisionID, i.Message)
+	}
+	return fmt.Sprintf("%s: chain %s record %d (decision %s): %s", i.Kind, i.ChainID, i.Seq, i.DecisionID, i.Message)
+}
+
+// Summary describes one chain found in the verified records.
+type Summary struct {
+	ChainID     string
+	Records     int
+	Checkpoints int
+	First       time.Time
+	Last        time.Time
+	// Unsigned is the number of records after the last valid checkpoint, which no
+	// signature vouches for. The records written last before a crash are unsigned.
+	Unsigned int
+	// Continues is the id of the chain this chain links to.
+	Continues string
+}
+
+type Report struct {
+	Chains []Summary
+	Issues []Issue
+}
+
+// OK reports whether no issue was found.
+func (r *Report) OK() bool {
+	return len(r.Issues) == 0
+}
+
+type chainState struct {
+	summary Summary
+	next    uint64
+	head    string
+	// first and last are the decision ids of the first and last record seen.
+	first string
+	last  string
+	// started is set when the first record of the chain was seen.
+	started bool
+	// checkpoints holds the hashes of the valid checkpoints, by position.
+	checkpoints map[uint64]string
+	continuedBy string
+}
+
+// Verifier checks decision records, in the order they were written, against their hash
+// chain and the checkpoint signatures.
+type Verifier struct {
+	key    ed25519.PublicKey
+	keyID  string
+	chains map[string]*chainState
+	order  []string
+	issues []Issue
+}
+
+func NewVerifier(key ed25519.PublicKey) *Verifier {
+	return &Verifier{
+		key:    key,
+		keyID:  KeyID(key),
+		chains: map[string]*chainState{},
+	}
+}
+
+// Add verifies the next decision record.
+func (v *Verifier) Add(d *api.Decision) {
+	id, ok := d.Annotations[decisionlog.AnnotationChainID]
+	if !ok {
+		v.issue(IssueUnchained, nil, 0, d, "record is not part of a hash chain")
+		return
+	}
+
+	c := v.chain(id)
+
+	seq, err := strconv.ParseUint(d.Annotations[decisionlog.AnnotationChainSeq], 10, 64)
+	if err != nil {
+		v.issue(IssueMalformed, c, 0, d, "invalid chain position")
+		return
+	}
+
+	if !strings.HasPrefix(d.Annotations[decisionlog.AnnotationChainHash], hashVersion+":") {
+		v.issue(IssueMalformed, c, seq, d, "unsupported hash version")
+		return
+	}
+
+	hash, err := Hash(d)
+	if err != nil {
+		v.issue(IssueMalformed, c, seq, d, err.Error())
+		return
+	}
+
+	if hash != d.Annotations[decisionlog.AnnotationChainHash] {
+		v.issue(IssueTampered, c, seq, d, "record content does not match its hash")
+	}
+
+	prev := d.Annotations[decisionlog.AnnotationChainPrev]
+
+	switch {
+	case seq < c.next:
+		v.issue(IssueOutOfOrder, c, seq, d, fmt.Sprintf("record found after record %d", c.next-1))
+		return
+	case seq > c.next:
+		v.issue(IssueGap, c, seq, d, fmt.Sprintf("records %d to %d are missing", c.next, seq-1))
+	case seq == 0:
+		v.link(c, d, prev)
+	case prev != c.head:
+		v.issue(IssueBrokenLink, c, seq, d, "previous hash does not match the previous record")
+	}
+
+	if c.summary.Records == 0 {
+		c.first = d.GetId()
+	}
+	c.last = d.GetId()
+
+	c.summary.Records++
+	c.summary.Unsigned++
+	if ts := d.GetTimestamp(); ts != nil {
+		if c.summary.First.IsZero() {
+			c.summary.First = ts.AsTime()
+		}
+		c.summary.Last = ts.AsTime()
+	}
+
+	if sig, ok := d.Annotations[decisionlog.AnnotationCheckpoint]; ok {
+		c.summary.Checkpoints++
+		if v.verifySignature(id, seq, prev, sig, d.Annotations[decisionlog.AnnotationCheckpointKey]) {
+			c.summary.Unsigned = 0
+			c.checkpoints[seq] = d.Annotations[decisionlog.AnnotationChainHash]
+		} else {
+			v.issue(IssueBadSignature, c, seq, d, "checkpoint signature is not valid for the verification key")
+		}
+	}
+
+	c.next = seq + 1
+	c.head = d.Annotations[decisionlog.AnnotationChainHash]
+}
+
+// link checks that the first record of a chain links to the last checkpoint of the chain
+// it continues. Only the first chain found may continue a chain that is not part of the
+// verified records, those are reported by Report.
+func (v *Verifier) link(c *chainState, d *api.Decision, prev string) {
+	c.started = true
+
+	linkID, ok := d.Annotations[decisionlog.AnnotationChainLinkID]
+	if !ok {
+		if prev != "" {
+			v.issue(IssueBrokenLink, c, 0, d, "previous hash set on a chain that does not continue another chain")
+		}
+		return
+	}
+	c.summary.Continues = linkID
+
+	linkSeq, err := strconv.ParseUint(d.Annotations[decisionlog.AnnotationChainLinkSeq], 10, 64)
+	if err != nil {
+		v.issue(IssueMalformed, c, 0, d, "invalid link position")
+		return
+	}
+
+	p, ok := v.chains[linkID]
+	if !ok {
+		return
+	}
+
+	if hash, ok := p.checkpoints[linkSeq]; !ok || hash != prev {
+		v.issue(IssueBrokenLink, c, 0, d, fmt.Sprintf("record does not link to checkpoint %d of chain %s", linkSeq, linkID))
+	}
+	if p.continuedBy != "" {
+		v.issue(IssueBrokenLink, c, 0, d, fmt.Sprintf("chain %s is already continued by chain %s", linkID, p.continuedBy))
+	}
+	p.continuedBy = c.summary.ChainID
+}
+
+// Report returns the chains and the issues found in the records added so far. Besides the
+// issues of the records, it reports the chains that do not continue the chains before them
+// and the chains that do not end with a valid checkpoint.
+func (v *Verifier) Report() *Report {
+	r := &Report{Issues: append([]Issue{}, v.issues...)}
+
+	for i, id := range v.order {
+		c := v.chains[id]
+		r.Chains = append(r.Chains, c.summary)
+
+		switch {
+		case i == 0 || !c.started:
+		case c.summary.Continues == "":
+			r.Issues = append(r.Issues, Issue{Kind: IssueBrokenLink, ChainID: id, DecisionID: c.first,
+				Message: "chain does not continue a previous chain"})
+		case v.chains[c.summary.Continues] == nil:
+			r.Issues = append(r.Issues, Issue{Kind: IssueGap, ChainID: id, DecisionID: c.first,
+				Message: fmt.Sprintf("chain %s it continues is missing", c.summary.Continues)})
+		}
+
+		if c.summary.Unsigned > 0 {
+			r.Issues = append(r.Issues, Issue{Kind: IssueUnsigned, ChainID: id, Seq: c.next - 1, DecisionID: c.last,
+				Message: fmt.Sprintf("%d records after the last checkpoint are not covered by a signature", c.summary.Unsigned)})
+		}
+	}
+
+	return r
+}
+
+func (v *Verifier) verifySignature(chainID string, seq uint64, prev, sig, keyID string) bool {
+	if keyID != v.keyID {
+		return false
+	}
+
+	b, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	return ed25519.Verify(v.key, checkpointMessage(chainID, seq, prev), b)
+}
+
+func (v *Verifier) chain(id string) *chainState {
+	c, ok := v.chains[id]
+	if !ok {
+		c = &chainState{summary: Summary{ChainID: id}, checkpoints: map[uint64]string{}}
+		v.chains[id] = c
+		v.order = append(v.order, id)
+	}
+	return c
+}
+
+func (v *Verifier) issue(kind string, c *chainState, seq uint64, d *api.Decision, msg string) {
+	i := Issue{Kind: kind, Seq: seq, DecisionID: d.GetId(), Message: msg}
+	if c != nil {
+		i.ChainID = c.summary.ChainID
+	}
+	v.issues = append(v.issues, i)
+}
@@ -0,0 +1,190 @@
+package decisionlog
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Config selects the decision logger implementation and holds its type specific configuration.
+type Config struct {
+	Type   string                 `json:"type"`
+	Config map[string]interface{} `json:"config"`
+	Async  AsyncConfig            `json:"async"`
+
+	// Settings of the file decision logger from before decision logger types, still accepted
+	// in place of its config block.
+	LogFilePath   string `json:"log_file_path"`
+	MaxFileSizeMB int    `json:"max_file_size_mb"`
+	MaxFileCount  int    `json:"max_file_count"`
+}
+
+// legacyType is the decision logger type the legacy settings configure.
+const legacyType = "file"
+
+// upgradeLegacy moves the legacy settings to the config block of the file decision logger.
+func (cfg *Config) upgradeLegacy() error {
+	if cfg.LogFilePath == "" && cfg.MaxFileSizeMB == 0 && cfg.MaxFileCount == 0 {
+		return nil
+	}
+
+	if cfg.Type != "" && cfg.Type != legacyType {
+		return errors.Errorf("log_file_path, max_file_size_mb and max_file_count only apply to the %s decision logger, not [%s]", legacyType, cfg.Type)
+	}
+
+	if len(cfg.Config) > 0 {
+		return errors.New("log_file_path, max_file_size_mb and max_file_count cannot be set with a config block")
+	}
+
+	cfg.Type = legacyType
+	cfg.Config = map[string]interface{}{}
+
+	if cfg.LogFilePath != "" {
+		cfg.Config["log_file_path"] = cfg.LogFilePath
+	}
+	if cfg.MaxFileSizeMB != 0 {
+		cfg.Config["max_file_size_mb"] = cfg.MaxFileSizeMB
+	}
+	if cfg.MaxFileCount != 0 {
+		cfg.Config["max_file_count"] = cfg.MaxFileCount
+	}
+
+	cfg.LogFilePath, cfg.MaxFileSizeMB, cfg.MaxFileCount = "", 0, 0
+
+	return nil
+}
+
+// Factory creates decision loggers of a given type.
+type Factory interface {
+	// Validate parses and validates the type specific configuration block.
+	Validate(config map[string]interface{}) (interface{}, error)
+	// New creates a decision logger from a configuration returned by Validate.
+	New(ctx context.Context, config interface{}, logger *zerolog.Logger) (DecisionLogger, error)
+}
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{}
+)
+
+// Register makes a decision logger factory available under the given type name.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("decisionlog: register factory is nil")
+	}
+
+	if _, ok := factories[name]; ok {
+		panic("decisionlog: register called twice for factory " + name)
+	}
+
+	factories[name] = factory
+}
+
+// Types returns the sorted list of registered decision logger types.
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	types := make([]string, 0, len(factories))
+	for name := range factories {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+func lookup(name string) (Factory, error) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	factory, ok := factories[name]
+	if !ok {
+		return nil, errors.Errorf("unknown decision logger type [%s], available types %v", name, Types())
+	}
+
+	return factory, nil
+}
+
+// Validate checks that the configured decision logger type exists and that its configuration is valid.
+// Legacy settings are moved to the config block of the file decision logger.
+func Validate(cfg *Config) error {
+	if err := cfg.upgradeLegacy(); err != nil {
+		return err
+	}
+
+	factory, err := lookup(cfg.Type)
+	if err != nil {
+		return err
+	}
+
+	if _, err := factory.Validate(cfg.Config); err != nil {
+		return errors.Wrapf(err, "invalid configuration for decision logger [%s]", cfg.Type)
+	}
+
+	if cfg.Async.Enabled {
+		async := cfg.Async
+		async.SetDefaults()
+		return errors.Wrap(async.Validate(), "invalid async decision logger configuration")
+	}
+
+	return nil
+}
+
+// New creates the decision logger selected by the configuration.
+func New(ctx context.Context, cfg *Config, logger *zerolog.Logger) (DecisionLogger, error) {
+	if err := cfg.upgradeLegacy(); err != nil {
+		return nil, err
+	}
+
+	factory, err := lookup(cfg.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := factory.Validate(cfg.Config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid configuration for decision logger [%s]", cfg.Type)
+	}
+
+	decisionLogger, err := factory.New(ctx, parsed, logger)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create decision logger [%s]", cfg.Type)
+	}
+
+	if !cfg.Async.Enabled {
+		return decisionLogger, nil
+	}
+
+	async, err := NewAsync(decisionLogger, &cfg.Async, cfg.Type, logger)
+	if err != nil {
+		decisionLogger.Shutdown()
+		return nil, errors.Wrap(err, "invalid async decision logger configuration")
+	}
+
+	return async, nil
+}
+
+// DecodeConfig decodes a type specific configuration block into out, using the json field tags.
+// Unknown keys are reported as errors.
+func DecodeConfig(config map[string]interface{}, out interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName:          "json",
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		Result:           out,
+	})
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(config)
+}
@@ -0,0 +1,100 @@
+package decisionlog_test
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConfig struct {
+	LogFilePath   string `json:"log_file_path"`
+	MaxFileSizeMB int    `json:"max_file_size_mb"`
+	MaxFileCount  int    `json:"max_file_count"`
+}
+
+// fakeFactory creates recorders, and rejects configurations without log_file_path.
+type fakeFactory struct {
+	created *fakeConfig
+}
+
+func (f *fakeFactory) Validate(config map[string]interface{}) (interface{}, error) {
+	cfg := &fakeConfig{}
+	if err := decisionlog.DecodeConfig(config, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.LogFilePath == "" {
+		return nil, errors.New("log_file_path not set")
+	}
+	return cfg, nil
+}
+
+func (f *fakeFactory) New(ctx context.Context, config interface{}, logger *zerolog.Logger) (decisionlog.DecisionLogger, error) {
+	f.created = config.(*fakeConfig)
+	return &recorder{}, nil
+}
+
+// the file type is registered by the topaz app, which the tests of this package do not import.
+var factory = &fakeFactory{}
+
+func init() {
+	decisionlog.Register("file", factory)
+	decisionlog.Register("test", &fakeFactory{})
+}
+
+func TestRegister(t *testing.T) {
+	assert.Equal(t, []string{"file", "test"}, decisionlog.Types())
+
+	assert.Panics(t, func() { decisionlog.Register("test", &fakeFactory{}) })
+	assert.Panics(t, func() { decisionlog.Register("other", nil) })
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		cfg   decisionlog.Config
+		valid bool
+	}{
+		"valid":        {decisionlog.Config{Type: "test", Config: map[string]interface{}{"log_file_path": "d.log"}}, true},
+		"unknown type": {decisionlog.Config{Type: "kafka"}, false},
+		"invalid":      {decisionlog.Config{Type: "test"}, false},
+		"unknown key":  {decisionlog.Config{Type: "test", Config: map[string]interface{}{"log_file_path": "d.log", "path": "d.log"}}, false},
+		"async":        {decisionlog.Config{Type: "test", Config: map[string]interface{}{"log_file_path": "d.log"}, Async: decisionlog.AsyncConfig{Enabled: true, OverflowPolicy: "wait"}}, false},
+		"legacy":       {decisionlog.Config{Type: "file", LogFilePath: "d.log", MaxFileCount: 2}, true},
+		"legacy type":  {decisionlog.Config{Type: "test", LogFilePath: "d.log"}, false},
+		"legacy block": {decisionlog.Config{Type: "file", LogFilePath: "d.log", Config: map[string]interface{}{"max_file_count": 2}}, false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := tc.cfg
+			err := decisionlog.Validate(&cfg)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestNewLegacy(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := &decisionlog.Config{Type: "file", LogFilePath: "d.log", MaxFileSizeMB: 50, MaxFileCount: 2}
+	decisionLogger, err := decisionlog.New(context.Background(), cfg, &logger)
+	require.NoError(t, err)
+	require.NoError(t, decisionLogger.Log(&api.Decision{Id: "1"}))
+
+	// the legacy settings configure the file decision logger.
+	assert.Equal(t, &fakeConfig{LogFilePath: "d.log", MaxFileSizeMB: 50, MaxFileCount: 2}, factory.created)
+	assert.Equal(t, "file", cfg.Type)
+	assert.Empty(t, cfg.LogFilePath)
+
+	// a configuration is validated and upgraded once.
+	require.NoError(t, decisionlog.Validate(cfg))
+}
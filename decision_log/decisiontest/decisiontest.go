@@ -0,0 +1,43 @@
+// Package decisiontest provides the decision fixtures shared by the tests of the readable decision loggers.
+package decisiontest
+
+import (
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Base is the time of the decisions taken at minute 0.
+var Base = time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+
+// Decision returns a decision of the acme tenant taken minute minutes after Base by the user with
+// the given email, on a resource owned by that user.
+func Decision(id string, minute int, email, path string, allowed bool) *api.Decision {
+	resource, _ := structpb.NewStruct(map[string]interface{}{
+		"id":    "doc-" + id,
+		"tags":  []interface{}{"a", 1.5, true, nil},
+		"owner": map[string]interface{}{"email": email},
+	})
+
+	return &api.Decision{
+		Id:        id,
+		Timestamp: timestamppb.New(Base.Add(time.Duration(minute) * time.Minute)),
+		Path:      path,
+		User:      &api.DecisionUser{Id: "id-" + email, Email: email},
+		TenantId:  proto.String("acme"),
+		Resource:  resource,
+		Outcomes:  map[string]bool{"allowed": allowed},
+	}
+}
+
+// IDs returns the ids of the decisions, in order.
+func IDs(decisions []*api.Decision) []string {
+	result := make([]string, 0, len(decisions))
+	for _, d := range decisions {
+		result = append(result, d.Id)
+	}
+	return result
+}
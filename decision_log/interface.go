@@ -8,3 +8,23 @@ type DecisionLogger interface {
 	Log(*api.Decision) error
 	Shutdown()
 }
+
+// BatchDecisionLogger is implemented by decision loggers that can write several decisions at once.
+type BatchDecisionLogger interface {
+	DecisionLogger
+	LogBatch([]*api.Decision) error
+}
+
+// QueuingDecisionLogger is implemented by decision loggers whose Log queues the decision and
+// returns without waiting for it to be written.
+type QueuingDecisionLogger interface {
+	DecisionLogger
+	Queuing() bool
+}
+
+// Queuing reports whether Log of the decision logger returns without waiting for the decision
+// to be written, unless a full queue makes it wait.
+func Queuing(l DecisionLogger) bool {
+	q, ok := l.(QueuingDecisionLogger)
+	return ok && q.Queuing()
+}
@@ -0,0 +1,66 @@
+package fanout
+
+import (
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	Sinks []SinkConfig `json:"sinks"`
+}
+
+// SinkConfig configures one of the decision loggers decisions are forwarded to.
+type SinkConfig struct {
+	// Name identifies the sink in metrics and log messages, it defaults to the sink type.
+	Name   string                  `json:"name"`
+	Type   string                  `json:"type"`
+	Config map[string]interface{}  `json:"config"`
+	Async  decisionlog.AsyncConfig `json:"async"`
+}
+
+func (cfg *Config) SetDefaults() {
+	for i := range cfg.Sinks {
+		if cfg.Sinks[i].Name == "" {
+			cfg.Sinks[i].Name = cfg.Sinks[i].Type
+		}
+
+		// every sink writes from its own queue, so a slow or failing sink does not hold up the others.
+		cfg.Sinks[i].Async.Enabled = true
+		cfg.Sinks[i].Async.SetDefaults()
+	}
+}
+
+func (cfg *Config) Validate() error {
+	if len(cfg.Sinks) == 0 {
+		return errors.New("no sinks configured")
+	}
+
+	names := map[string]bool{}
+
+	for i := range cfg.Sinks {
+		sink := &cfg.Sinks[i]
+
+		if names[sink.Name] {
+			return errors.Errorf("duplicate sink name [%s], set a unique name for each sink", sink.Name)
+		}
+		names[sink.Name] = true
+
+		if sink.Async.OverflowPolicy == decisionlog.OverflowBlock {
+			return errors.Errorf("sink [%s]: overflow policy %s would block the other sinks", sink.Name, decisionlog.OverflowBlock)
+		}
+
+		if err := decisionlog.Validate(sink.decisionLoggerConfig()); err != nil {
+			return errors.Wrapf(err, "sink [%s]", sink.Name)
+		}
+	}
+
+	return nil
+}
+
+func (sink *SinkConfig) decisionLoggerConfig() *decisionlog.Config {
+	return &decisionlog.Config{
+		Type:   sink.Type,
+		Config: sink.Config,
+		Async:  sink.Async,
+	}
+}
@@ -0,0 +1,133 @@
+package fanout
+
+import (
+	"context"
+	"sync"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type sink struct {
+	name   string
+	logger *decisionlog.AsyncLogger
+}
+
+// Logger forwards every decision to all configured sinks. Each sink writes from its
+// own queue, so a slow or failing sink neither blocks nor fails the others.
+//
+// The same decision instance is handed to all sinks, sinks must not modify it.
+type Logger struct {
+	sinks  []sink
+	logger *zerolog.Logger
+}
+
+var (
+	_ decisionlog.QueuingDecisionLogger = (*Logger)(nil)
+	_ decisionlog.DecisionReader        = (*Logger)(nil)
+)
+
+func New(ctx context.Context, cfg *Config, logger *zerolog.Logger) (*Logger, error) {
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	newLogger := logger.With().Str("component", "decision-log-fanout").Logger()
+	l := &Logger{logger: &newLogger}
+
+	for i := range cfg.Sinks {
+		sinkCfg := &cfg.Sinks[i]
+
+		// the sink is wrapped below, so its queue is labeled with the sink name instead of its type.
+		dlCfg := sinkCfg.decisionLoggerConfig()
+		dlCfg.Async.Enabled = false
+
+		decisionLogger, err := decisionlog.New(ctx, dlCfg, logger)
+		if err != nil {
+			l.Shutdown()
+			return nil, errors.Wrapf(err, "failed to create sink [%s]", sinkCfg.Name)
+		}
+
+		async, err := decisionlog.NewAsync(decisionLogger, &sinkCfg.Async, sinkCfg.Name, logger)
+		if err != nil {
+			decisionLogger.Shutdown()
+			l.Shutdown()
+			return nil, errors.Wrapf(err, "failed to create sink [%s]", sinkCfg.Name)
+		}
+
+		l.sinks = append(l.sinks, sink{name: sinkCfg.Name, logger: async})
+	}
+
+	return l, nil
+}
+
+// Log enqueues the decision on every sink. It only fails when no sink accepted the decision.
+func (l *Logger) Log(d *api.Decision) error {
+	var lastErr error
+	accepted := 0
+
+	for _, s := range l.sinks {
+		if err := s.logger.Log(d); err != nil {
+			lastErr = errors.Wrapf(err, "sink [%s]", s.name)
+			continue
+		}
+		accepted++
+	}
+
+	if accepted == 0 && lastErr != nil {
+		return lastErr
+	}
+
+	return nil
+}
+
+// Queuing reports that decisions are written from the queues of the sinks.
+func (l *Logger) Queuing() bool {
+	return true
+}
+
+// Shutdown drains and shuts down all sinks in parallel and reports their final counts.
+func (l *Logger) Shutdown() {
+	var wg sync.WaitGroup
+
+	for _, s := range l.sinks {
+		wg.Add(1)
+		go func(s sink) {
+			defer wg.Done()
+			s.logger.Shutdown()
+		}(s)
+	}
+
+	wg.Wait()
+
+	for name, stats := range l.Stats() {
+		l.logger.Info().Str("sink", name).
+			Uint64("written", stats.Written).
+			Uint64("failed", stats.Failed).
+			Uint64("dropped", stats.Dropped).
+			Msg("decision log sink stopped")
+	}
+}
+
+// Search reads decisions back from the first sink that supports it.
+func (l *Logger) Search(ctx context.Context, q *decisionlog.Query) ([]*api.Decision, error) {
+	for _, s := range l.sinks {
+		if r, ok := decisionlog.Reader(s.logger); ok {
+			return r.Search(ctx, q)
+		}
+	}
+
+	return nil, decisionlog.ErrNotReadable
+}
+
+// Stats returns the counters of each sink by sink name.
+func (l *Logger) Stats() map[string]decisionlog.AsyncStats {
+	stats := make(map[string]decisionlog.AsyncStats, len(l.sinks))
+	for _, s := range l.sinks {
+		stats[s.name] = s.logger.Stats()
+	}
+	return stats
+}
@@ -0,0 +1,103 @@
+package fanout_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/logger/fanout"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu  sync.Mutex
+	ids []string
+	err error
+}
+
+func (r *recorder) Log(d *api.Decision) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, d.Id)
+	return nil
+}
+
+func (r *recorder) Shutdown() {}
+
+type factory struct {
+	sink *recorder
+}
+
+func (factory) Validate(config map[string]interface{}) (interface{}, error) {
+	return nil, nil
+}
+
+func (f factory) New(ctx context.Context, config interface{}, logger *zerolog.Logger) (decisionlog.DecisionLogger, error) {
+	return f.sink, nil
+}
+
+var (
+	healthy = &recorder{}
+	broken  = &recorder{err: errors.New("collector unavailable")}
+)
+
+// nolint: gochecknoinits
+func init() {
+	decisionlog.Register("fanout-test-healthy", factory{sink: healthy})
+	decisionlog.Register("fanout-test-broken", factory{sink: broken})
+}
+
+func TestFanoutIsolatesSinks(t *testing.T) {
+	logger := zerolog.Nop()
+
+	l, err := fanout.New(context.Background(), &fanout.Config{
+		Sinks: []fanout.SinkConfig{
+			{Type: "fanout-test-healthy"},
+			{Name: "remote", Type: "fanout-test-broken"},
+		},
+	}, &logger)
+	require.NoError(t, err)
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, l.Log(&api.Decision{Id: id}))
+	}
+
+	l.Shutdown()
+
+	assert.Equal(t, []string{"1", "2", "3"}, healthy.ids)
+
+	stats := l.Stats()
+	assert.Equal(t, uint64(3), stats["fanout-test-healthy"].Written)
+	assert.Equal(t, uint64(0), stats["fanout-test-healthy"].Failed)
+	assert.Equal(t, uint64(0), stats["remote"].Written)
+	assert.Equal(t, uint64(3), stats["remote"].Failed)
+}
+
+func TestFanoutInvalidConfig(t *testing.T) {
+	tests := map[string]*fanout.Config{
+		"no sinks": {},
+		"duplicate names": {Sinks: []fanout.SinkConfig{
+			{Type: "fanout-test-healthy"},
+			{Type: "fanout-test-healthy"},
+		}},
+		"unknown type": {Sinks: []fanout.SinkConfig{{Type: "kafka"}}},
+		"blocking sink": {Sinks: []fanout.SinkConfig{
+			{Type: "fanout-test-healthy", Async: decisionlog.AsyncConfig{OverflowPolicy: decisionlog.OverflowBlock}},
+		}},
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
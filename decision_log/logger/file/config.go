@@ -2,12 +2,35 @@ package file
 
 import (
 	"os"
+
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+)
+
+const (
+	RotationDaily  = "daily"
+	RotationHourly = "hourly"
 )
 
 type Config struct {
 	LogFilePath   string `json:"log_file_path"`
 	MaxFileSizeMB int    `json:"max_file_size_mb"`
 	MaxFileCount  int    `json:"max_file_count"`
+	// Rotation additionally rotates the file at the start of every day or hour.
+	Rotation string `json:"rotation"`
+	// Compress gzips rotated files.
+	Compress bool `json:"compress"`
+	// MaxAgeDays removes rotated files older than the given number of days.
+	MaxAgeDays int `json:"max_age_days"`
+	// LocalTime uses the local time zone, rather than UTC, for the names of rotated
+	// files and for the daily and hourly rotation boundaries.
+	LocalTime bool `json:"local_time"`
+	// ReopenOnSIGHUP closes the file on SIGHUP and reopens it on the next write,
+	// for use with an external logrotate.
+	ReopenOnSIGHUP bool `json:"reopen_on_sighup"`
+	// Schema of the records, topaz (default) or opa. Records in the opa schema are
+	// written one per line and cannot be searched.
+	Schema string `json:"schema"`
 }
 
 func (cfg *Config) SetDefaults() {
@@ -22,7 +45,29 @@ func (cfg *Config) SetDefaults() {
 	if cfg.MaxFileSizeMB == 0 {
 		cfg.MaxFileSizeMB = 50
 	}
-	if cfg.MaxFileCount == 0 {
+	if cfg.Schema == "" {
+		cfg.Schema = decisionlog.SchemaTopaz
+	}
+	// with time based retention all rotated files younger than max_age_days are kept.
+	if cfg.MaxFileCount == 0 && cfg.MaxAgeDays == 0 {
 		cfg.MaxFileCount = 2
 	}
 }
+
+func (cfg *Config) Validate() error {
+	switch cfg.Rotation {
+	case "", RotationDaily, RotationHourly:
+	default:
+		return errors.Errorf("unknown rotation [%s], must be %s or %s", cfg.Rotation, RotationDaily, RotationHourly)
+	}
+
+	if err := decisionlog.ValidateSchema(cfg.Schema); err != nil {
+		return err
+	}
+
+	if cfg.MaxFileSizeMB < 0 || cfg.MaxFileCount < 0 || cfg.MaxAgeDays < 0 {
+		return errors.New("max_file_size_mb, max_file_count and max_age_days must be positive")
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package file
 import (
 	"context"
 	"encoding/json"
+	"sync"
 
 	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
 	decisionlog "github.com/aserto-dev/topaz/decision_log"
@@ -12,30 +13,101 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-type fileLogger zerolog.Logger
+type fileLogger struct {
+	cfg    *Config
+	file   *lumberjack.Logger
+	writer zerolog.Logger
+	logger *zerolog.Logger
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+var (
+	_ decisionlog.DecisionLogger = (*fileLogger)(nil)
+	_ decisionlog.DecisionReader = (*fileLogger)(nil)
+)
 
 func New(ctx context.Context, cfg *Config, logger *zerolog.Logger) (decisionlog.DecisionLogger, error) {
 	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 
 	ljLogger := &lumberjack.Logger{
 		Filename:   cfg.LogFilePath,
 		MaxSize:    cfg.MaxFileSizeMB,
 		MaxBackups: cfg.MaxFileCount,
+		MaxAge:     cfg.MaxAgeDays,
+		Compress:   cfg.Compress,
+		LocalTime:  cfg.LocalTime,
 	}
 
-	decisionLogger := zerolog.New(ljLogger)
-	return (*fileLogger)(&decisionLogger), nil
+	newLogger := logger.With().Str("component", "decision-log-file").Str("path", cfg.LogFilePath).Logger()
+
+	l := &fileLogger{
+		cfg:    cfg,
+		file:   ljLogger,
+		writer: zerolog.New(ljLogger),
+		logger: &newLogger,
+		stop:   make(chan struct{}),
+	}
+
+	if cfg.Rotation != "" {
+		l.wg.Add(1)
+		go l.rotate()
+	}
+
+	if cfg.ReopenOnSIGHUP {
+		l.wg.Add(1)
+		go l.reopenOnSIGHUP(notifySIGHUP())
+	}
+
+	if cfg.Schema == decisionlog.SchemaOPA {
+		return &writeOnlyLogger{l}, nil
+	}
+
+	return l, nil
+}
+
+// writeOnlyLogger hides the Search method of a file logger whose records cannot be read back.
+type writeOnlyLogger struct {
+	decisionlog.DecisionLogger
 }
 
 func (l *fileLogger) Log(d *api.Decision) error {
+	if l.cfg.Schema == decisionlog.SchemaOPA {
+		return l.logOPA(d)
+	}
+
 	bytes, err := json.Marshal(d)
 	if err != nil {
 		return errors.Wrap(err, "error marshaling decision")
 	}
 
-	(*zerolog.Logger)(l).Log().Msg(string(bytes))
+	l.writer.Log().Msg(string(bytes))
 	return nil
 }
 
+// logOPA writes the decision as an OPA decision log event, one event per line.
+func (l *fileLogger) logOPA(d *api.Decision) error {
+	bytes, err := json.Marshal(decisionlog.NewOPAEvent(d))
+	if err != nil {
+		return errors.Wrap(err, "error marshaling decision")
+	}
+
+	_, err = l.file.Write(append(bytes, '\n'))
+	return errors.Wrap(err, "failed to write decision")
+}
+
 func (l *fileLogger) Shutdown() {
+	l.once.Do(func() {
+		close(l.stop)
+		l.wg.Wait()
+
+		if err := l.file.Close(); err != nil {
+			l.logger.Error().Err(err).Msg("failed to close decision log file")
+		}
+	})
 }
@@ -0,0 +1,216 @@
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// backupTimeFormat is the timestamp lumberjack appends to the name of rotated files.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// envelope is the line written by the zerolog writer around each decision.
+type envelope struct {
+	Message string `json:"message"`
+}
+
+// Search scans the current log file and its rotated backups. Files last written
+// before the start of the queried time range are skipped.
+func (l *fileLogger) Search(ctx context.Context, q *decisionlog.Query) ([]*api.Decision, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	files, err := logFiles(l.cfg.LogFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	from, _ := q.Range()
+
+	var matches []*api.Decision
+
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			// the file was rotated away since it was listed.
+			continue
+		}
+
+		if !from.IsZero() && fi.ModTime().Before(from) {
+			continue
+		}
+
+		if err := readFile(path, func(d *api.Decision) {
+			if q.Match(d) {
+				matches = append(matches, d)
+			}
+		}); err != nil {
+			return nil, errors.Wrapf(err, "failed to read decision log file [%s]", path)
+		}
+
+		if len(matches) > 2*q.Limit {
+			matches = q.Page(matches)
+		}
+	}
+
+	return q.Page(matches), nil
+}
+
+// ReadLogSet calls fn for every decision in the log file at path and in its rotated
+// backups, in the order they were written. Files with one JSON decision per line are
+// read as well.
+func ReadLogSet(path string, fn func(*api.Decision)) error {
+	files, err := logFiles(path)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := readFile(f, fn); err != nil {
+			return errors.Wrapf(err, "failed to read decision log file [%s]", f)
+		}
+	}
+
+	return nil
+}
+
+// logFiles returns the backups created by lumberjack when rotating the log file, oldest
+// first, followed by the log file itself.
+func logFiles(path string) ([]string, error) {
+	dir := filepath.Dir(path)
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	prefix := strings.TrimSuffix(name, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to list decision log files")
+	}
+
+	var files []string
+	current := false
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		switch {
+		case e.Name() == name:
+			current = true
+		case isBackup(e.Name(), prefix, ext):
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+
+	// backup names only differ by their timestamp, entries are sorted by name.
+	if current {
+		files = append(files, path)
+	}
+
+	return files, nil
+}
+
+func isBackup(name, prefix, ext string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+
+	ts := strings.TrimPrefix(name, prefix)
+	ts = strings.TrimSuffix(ts, ".gz")
+	if !strings.HasSuffix(ts, ext) {
+		return false
+	}
+
+	_, err := time.Parse(backupTimeFormat, strings.TrimSuffix(ts, ext))
+	return err == nil
+}
+
+// readFile calls fn for every decision in the file. Lines that cannot be decoded,
+// such as a partially written last line, are skipped.
+func readFile(path string, fn func(*api.Decision)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if d, decodeErr := decodeLine(line); decodeErr == nil {
+				fn(d)
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// decodeLine decodes a line written by the file logger, or a line holding just a
+// decision, as written to the dead letter file of the http logger.
+func decodeLine(line []byte) (*api.Decision, error) {
+	var e envelope
+	if err := json.Unmarshal(line, &e); err != nil {
+		return nil, err
+	}
+
+	if e.Message == "" {
+		return decodeDecision(line)
+	}
+
+	return decodeDecision([]byte(e.Message))
+}
+
+// decodeDecision decodes a decision encoded either with protojson or, as written by
+// the file logger, with encoding/json.
+func decodeDecision(b []byte) (*api.Decision, error) {
+	d := &api.Decision{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, d); err == nil {
+		return d, nil
+	}
+
+	d = &api.Decision{}
+	if err := json.Unmarshal(b, d); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
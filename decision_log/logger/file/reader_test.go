@@ -0,0 +1,109 @@
+package file_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/decisiontest"
+	"github.com/aserto-dev/topaz/decision_log/logger/file"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSearch(t *testing.T) {
+	dir := t.TempDir()
+	logger := zerolog.Nop()
+
+	// a backup rotated by lumberjack, written with the same logger.
+	backup, err := file.New(context.Background(), &file.Config{
+		LogFilePath: filepath.Join(dir, "decisions-2023-06-01T10-30-00.000.log"),
+	}, &logger)
+	require.NoError(t, err)
+	require.NoError(t, backup.Log(decisiontest.Decision("1", 1, "alice@acmecorp.com", "peoplefinder.GET.users", true)))
+	require.NoError(t, backup.Log(decisiontest.Decision("2", 2, "bob@acmecorp.com", "peoplefinder.GET.users", false)))
+	backup.Shutdown()
+
+	current, err := file.New(context.Background(), &file.Config{
+		LogFilePath: filepath.Join(dir, "decisions.log"),
+	}, &logger)
+	require.NoError(t, err)
+	require.NoError(t, current.Log(decisiontest.Decision("3", 42, "alice@acmecorp.com", "peoplefinder.POST.users", false)))
+	require.NoError(t, current.Log(decisiontest.Decision("4", 43, "alice@acmecorp.com", "todo.GET.todos", true)))
+
+	// unrelated files and partially written lines are ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.log"), []byte(`{"message":"{}"}`), 0o600))
+	f, err := os.OpenFile(filepath.Join(dir, "decisions.log"), os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"message":"{\"id\":\"5\",`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reader, ok := decisionlog.Reader(current)
+	require.True(t, ok)
+
+	tests := map[string]struct {
+		query    decisionlog.Query
+		expected []string
+	}{
+		"all newest first": {decisionlog.Query{}, []string{"4", "3", "2", "1"}},
+		"limit":            {decisionlog.Query{Limit: 2}, []string{"4", "3"}},
+		"user by email":    {decisionlog.Query{User: "Alice@acmecorp.com"}, []string{"4", "3", "1"}},
+		"user by id":       {decisionlog.Query{User: "id-bob@acmecorp.com"}, []string{"2"}},
+		"denies":           {decisionlog.Query{Outcome: decisionlog.OutcomeDeny}, []string{"3", "2"}},
+		"path glob":        {decisionlog.Query{Path: "peoplefinder.*.users"}, []string{"3", "2", "1"}},
+		"tenant":           {decisionlog.Query{TenantID: "other"}, []string{}},
+		"time range": {
+			decisionlog.Query{From: decisiontest.Base.Add(2 * time.Minute), To: decisiontest.Base.Add(43 * time.Minute)},
+			[]string{"3", "2"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			q := tc.query
+			result, err := reader.Search(context.Background(), &q)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, decisiontest.IDs(result))
+		})
+	}
+
+	result, err := reader.Search(context.Background(), &decisionlog.Query{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.True(t, proto.Equal(decisiontest.Decision("4", 43, "alice@acmecorp.com", "todo.GET.todos", true), result[0]))
+}
+
+func TestOPASchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	logger := zerolog.Nop()
+
+	l, err := file.New(context.Background(), &file.Config{LogFilePath: path, Schema: decisionlog.SchemaOPA}, &logger)
+	require.NoError(t, err)
+	require.NoError(t, l.Log(decisiontest.Decision("1", 1, "alice@acmecorp.com", "peoplefinder.GET.users", true)))
+	require.NoError(t, l.Log(decisiontest.Decision("2", 2, "bob@acmecorp.com", "peoplefinder.GET.users", false)))
+	l.Shutdown()
+
+	// records in the opa schema cannot be read back as decisions.
+	_, ok := decisionlog.Reader(l)
+	assert.False(t, ok)
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, "2", event["decision_id"])
+	assert.Equal(t, "peoplefinder/GET/users", event["path"])
+	assert.Equal(t, map[string]interface{}{"allowed": false}, event["result"])
+}
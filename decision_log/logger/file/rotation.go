@@ -0,0 +1,78 @@
+package file
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// rotate rotates the file at every rotation boundary. Empty files are not rotated,
+// so idle periods do not leave empty rotated files behind.
+func (l *fileLogger) rotate() {
+	defer l.wg.Done()
+
+	for {
+		timer := time.NewTimer(time.Until(nextRotation(time.Now(), l.cfg.Rotation, l.location())))
+
+		select {
+		case <-l.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if fi, err := os.Stat(l.cfg.LogFilePath); err != nil || fi.Size() == 0 {
+			continue
+		}
+
+		if err := l.file.Rotate(); err != nil {
+			l.logger.Error().Err(err).Msg("failed to rotate decision log file")
+		}
+	}
+}
+
+// notifySIGHUP returns a channel receiving SIGHUP, instead of the default termination of the process.
+func notifySIGHUP() chan os.Signal {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	return hup
+}
+
+// reopenOnSIGHUP closes the file on SIGHUP. The next write opens the file at the
+// configured path again, which is a new file once an external logrotate moved it away.
+func (l *fileLogger) reopenOnSIGHUP(hup chan os.Signal) {
+	defer l.wg.Done()
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-hup:
+			if err := l.file.Close(); err != nil {
+				l.logger.Error().Err(err).Msg("failed to reopen decision log file")
+				continue
+			}
+			l.logger.Info().Msg("decision log file reopened")
+		}
+	}
+}
+
+func (l *fileLogger) location() *time.Location {
+	if l.cfg.LocalTime {
+		return time.Local
+	}
+	return time.UTC
+}
+
+// nextRotation returns the start of the day or hour following now, in the given location.
+func nextRotation(now time.Time, rotation string, loc *time.Location) time.Time {
+	now = now.In(loc)
+
+	if rotation == RotationHourly {
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour()+1, 0, 0, 0, loc)
+	}
+
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,58 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRotation(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	now := time.Date(2023, 6, 1, 23, 42, 10, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC), nextRotation(now, RotationDaily, time.UTC))
+	assert.Equal(t, time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC), nextRotation(now, RotationHourly, time.UTC))
+	assert.Equal(t, time.Date(2023, 6, 1, 20, 0, 0, 0, ny), nextRotation(now, RotationHourly, ny))
+	assert.Equal(t, time.Date(2023, 6, 2, 0, 0, 0, 0, ny), nextRotation(now, RotationDaily, ny))
+}
+
+func TestReopenOnSIGHUP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	logger := zerolog.Nop()
+
+	l, err := New(context.Background(), &Config{LogFilePath: path, ReopenOnSIGHUP: true}, &logger)
+	require.NoError(t, err)
+	defer l.Shutdown()
+
+	require.NoError(t, l.Log(&api.Decision{Id: "1"}))
+
+	// an external logrotate moves the file away and signals the process.
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	assert.Eventually(t, func() bool {
+		require.NoError(t, l.Log(&api.Decision{Id: "2"}))
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	rotated, err := os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Contains(t, string(rotated), `\"id\":\"1\"`)
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(current), `\"id\":\"1\"`)
+	assert.True(t, strings.Contains(string(current), `\"id\":\"2\"`))
+}
@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	// Address of the collector, host:port.
+	Address string `json:"address"`
+	// Method is the full name of the client streaming method that receives the decisions,
+	// e.g. /acme.collector.v1.DecisionCollector/Collect.
+	Method     string        `json:"method"`
+	APIKey     string        `json:"api_key"`
+	TenantID   string        `json:"tenant_id"`
+	Insecure   bool          `json:"insecure"`
+	CACertPath string        `json:"ca_cert_path"`
+	Timeout    time.Duration `json:"timeout"`
+}
+
+func (cfg *Config) SetDefaults() {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Address == "" {
+		return errors.New("address not set")
+	}
+
+	if cfg.Method == "" {
+		return errors.New("method not set")
+	}
+	if !strings.HasPrefix(cfg.Method, "/") || strings.Count(cfg.Method, "/") != 2 {
+		return errors.Errorf("invalid method [%s], must be /<package>.<service>/<method>", cfg.Method)
+	}
+
+	if cfg.Timeout < 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	grpcc "github.com/aserto-dev/go-aserto/client"
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// grpcLogger streams batches of decisions to a collector. Each batch is sent on its own
+// client stream, as aserto.authorizer.v2.api.Decision messages, and is delivered once
+// the collector closes the stream with a google.protobuf.Empty response.
+type grpcLogger struct {
+	// ctx is not derived from the application context, so queued decisions
+	// can still be delivered while the application shuts down.
+	ctx    context.Context
+	cancel context.CancelFunc
+	cfg    *Config
+	logger *zerolog.Logger
+
+	mu   sync.Mutex
+	conn *grpcc.Connection
+}
+
+var _ decisionlog.BatchDecisionLogger = (*grpcLogger)(nil)
+
+var streamDesc = &grpc.StreamDesc{ClientStreams: true}
+
+func New(ctx context.Context, cfg *Config, logger *zerolog.Logger) (decisionlog.DecisionLogger, error) {
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	newLogger := logger.With().Str("component", "decision-log-grpc").Str("address", cfg.Address).Logger()
+
+	sendCtx, cancel := context.WithCancel(context.Background())
+
+	return &grpcLogger{
+		ctx:    sendCtx,
+		cancel: cancel,
+		cfg:    cfg,
+		logger: &newLogger,
+	}, nil
+}
+
+func (l *grpcLogger) Log(d *api.Decision) error {
+	return l.LogBatch([]*api.Decision{d})
+}
+
+func (l *grpcLogger) LogBatch(batch []*api.Decision) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	conn, err := l.connect()
+	if err != nil {
+		return err
+	}
+
+	if err := l.send(conn, batch); err != nil {
+		// the next batch connects again, in case the collector moved.
+		l.disconnect(conn)
+		return errors.Wrapf(err, "failed to deliver %d decisions", len(batch))
+	}
+
+	return nil
+}
+
+func (l *grpcLogger) Shutdown() {
+	l.cancel()
+
+	l.mu.Lock()
+	conn := l.conn
+	l.mu.Unlock()
+
+	if conn != nil {
+		l.disconnect(conn)
+	}
+}
+
+func (l *grpcLogger) send(conn *grpcc.Connection, batch []*api.Decision) error {
+	ctx, cancel := context.WithTimeout(l.ctx, l.cfg.Timeout)
+	defer cancel()
+
+	stream, err := conn.Conn.NewStream(ctx, streamDesc, l.cfg.Method)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range batch {
+		if err := stream.SendMsg(d); err != nil {
+			// the status of the stream is returned by RecvMsg.
+			break
+		}
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	return stream.RecvMsg(&emptypb.Empty{})
+}
+
+// connect returns the connection to the collector, connecting on first use so that
+// Topaz starts while the collector is unreachable.
+func (l *grpcLogger) connect() (*grpcc.Connection, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		return l.conn, nil
+	}
+
+	conn, err := grpcc.NewConnection(l.ctx,
+		grpcc.WithAddr(l.cfg.Address),
+		grpcc.WithAPIKeyAuth(l.cfg.APIKey),
+		grpcc.WithTenantID(l.cfg.TenantID),
+		grpcc.WithInsecure(l.cfg.Insecure),
+		grpcc.WithCACertPath(l.cfg.CACertPath),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to decision collector")
+	}
+
+	l.conn = conn
+	return conn, nil
+}
+
+func (l *grpcLogger) disconnect(conn *grpcc.Connection) {
+	l.mu.Lock()
+	if l.conn == conn {
+		l.conn = nil
+	}
+	l.mu.Unlock()
+
+	if cc, ok := conn.Conn.(*grpc.ClientConn); ok {
+		if err := cc.Close(); err != nil {
+			l.logger.Debug().Err(err).Msg("failed to close collector connection")
+		}
+	}
+}
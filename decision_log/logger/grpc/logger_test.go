@@ -0,0 +1,114 @@
+package grpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	grpclogger "github.com/aserto-dev/topaz/decision_log/logger/grpc"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const method = "/acme.collector.v1.DecisionCollector/Collect"
+
+type collector struct {
+	mu       sync.Mutex
+	received []string
+}
+
+// handle receives the decisions of the collect method, any other method is unimplemented.
+func (c *collector) handle(srv interface{}, stream grpc.ServerStream) error {
+	if m, _ := grpc.MethodFromServerStream(stream); m != method {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", m)
+	}
+
+	for {
+		d := &api.Decision{}
+		if err := stream.RecvMsg(d); err != nil {
+			break
+		}
+
+		c.mu.Lock()
+		c.received = append(c.received, d.Id)
+		c.mu.Unlock()
+	}
+
+	return stream.SendMsg(&emptypb.Empty{})
+}
+
+func selfSigned(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func startCollector(t *testing.T) (*collector, string) {
+	c := &collector{}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewServerTLSFromCert(&[]tls.Certificate{selfSigned(t)}[0])),
+		grpc.UnknownServiceHandler(c.handle),
+	)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return c, lis.Addr().String()
+}
+
+func TestLogBatch(t *testing.T) {
+	c, addr := startCollector(t)
+	logger := zerolog.Nop()
+
+	l, err := grpclogger.New(context.Background(), &grpclogger.Config{Address: addr, Method: method, Insecure: true}, &logger)
+	require.NoError(t, err)
+	defer l.Shutdown()
+
+	batcher := l.(interface{ LogBatch([]*api.Decision) error })
+	require.NoError(t, batcher.LogBatch([]*api.Decision{{Id: "1"}, {Id: "2"}}))
+	require.NoError(t, l.Log(&api.Decision{Id: "3"}))
+
+	assert.Equal(t, []string{"1", "2", "3"}, c.received)
+}
+
+func TestLogBatchUnknownMethod(t *testing.T) {
+	_, addr := startCollector(t)
+	logger := zerolog.Nop()
+
+	l, err := grpclogger.New(context.Background(), &grpclogger.Config{Address: addr, Method: "/acme.Other/Collect", Insecure: true}, &logger)
+	require.NoError(t, err)
+	defer l.Shutdown()
+
+	err = l.Log(&api.Decision{Id: "1"})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"net/url"
+	"time"
+
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+type Config struct {
+	URL            string            `json:"url"`
+	Format         string            `json:"format"`
+	Schema         string            `json:"schema"`
+	Headers        map[string]string `json:"headers"`
+	Auth           AuthConfig        `json:"auth"`
+	Gzip           bool              `json:"gzip"`
+	Timeout        time.Duration     `json:"timeout"`
+	MaxRetries     *int              `json:"max_retries"`
+	InitialBackoff time.Duration     `json:"initial_backoff"`
+	MaxBackoff     time.Duration     `json:"max_backoff"`
+	DeadLetterPath string            `json:"dead_letter_path"`
+}
+
+type AuthConfig struct {
+	BearerToken string `json:"bearer_token"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+}
+
+func (cfg *Config) SetDefaults() {
+	if cfg.Format == "" {
+		cfg.Format = FormatJSON
+	}
+	if cfg.Schema == "" {
+		cfg.Schema = decisionlog.SchemaTopaz
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxRetries == nil {
+		maxRetries := 3
+		cfg.MaxRetries = &maxRetries
+	}
+	if cfg.InitialBackoff == 0 {
+		cfg.InitialBackoff = 500 * time.Millisecond
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.URL == "" {
+		return errors.New("url not set")
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return errors.Wrap(err, "invalid url")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Errorf("unsupported url scheme [%s]", u.Scheme)
+	}
+
+	if cfg.Format != FormatJSON && cfg.Format != FormatNDJSON {
+		return errors.Errorf("unknown format [%s], must be %s or %s", cfg.Format, FormatJSON, FormatNDJSON)
+	}
+
+	if err := decisionlog.ValidateSchema(cfg.Schema); err != nil {
+		return err
+	}
+
+	if cfg.Auth.BearerToken != "" && cfg.Auth.Username != "" {
+		return errors.New("auth.bearer_token and auth.username are mutually exclusive")
+	}
+
+	if cfg.MaxRetries != nil && *cfg.MaxRetries < 0 {
+		return errors.New("max_retries must be positive or 0")
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"context"
+
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/rs/zerolog"
+)
+
+// Type is the decision_logger.type value that selects the http decision logger.
+const Type = "http"
+
+type Factory struct{}
+
+func (Factory) Validate(config map[string]interface{}) (interface{}, error) {
+	cfg := &Config{}
+	if err := decisionlog.DecodeConfig(config, cfg); err != nil {
+		return nil, err
+	}
+
+	cfg.SetDefaults()
+
+	return cfg, cfg.Validate()
+}
+
+func (Factory) New(ctx context.Context, config interface{}, logger *zerolog.Logger) (decisionlog.DecisionLogger, error) {
+	return New(ctx, config.(*Config), logger)
+}
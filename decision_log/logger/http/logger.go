@@ -0,0 +1,270 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type httpLogger struct {
+	// ctx is not derived from the application context, so queued decisions
+	// can still be delivered while the application shuts down.
+	ctx    context.Context
+	cancel context.CancelFunc
+	cfg    *Config
+	client *http.Client
+	logger *zerolog.Logger
+
+	deadLetterMu sync.Mutex
+}
+
+var _ decisionlog.BatchDecisionLogger = (*httpLogger)(nil)
+
+// errPermanent marks a failed delivery that must not be retried.
+type errPermanent struct {
+	error
+}
+
+func New(ctx context.Context, cfg *Config, logger *zerolog.Logger) (decisionlog.DecisionLogger, error) {
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	if cfg.DeadLetterPath != "" {
+		if err := os.MkdirAll(filepath.Dir(cfg.DeadLetterPath), 0o700); err != nil {
+			return nil, errors.Wrap(err, "failed to create dead letter directory")
+		}
+	}
+
+	newLogger := logger.With().Str("component", "decision-log-http").Str("url", cfg.URL).Logger()
+
+	sendCtx, cancel := context.WithCancel(context.Background())
+
+	return &httpLogger{
+		ctx:    sendCtx,
+		cancel: cancel,
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: &newLogger,
+	}, nil
+}
+
+func (l *httpLogger) Log(d *api.Decision) error {
+	return l.LogBatch([]*api.Decision{d})
+}
+
+// LogBatch posts the batch to the configured url. Batches that still fail after all
+// retries are appended to the dead letter file, when one is configured.
+func (l *httpLogger) LogBatch(batch []*api.Decision) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	body, err := l.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	err = l.send(body)
+	if err == nil {
+		return nil
+	}
+
+	l.logger.Error().Err(err).Int("count", len(batch)).Msg("failed to deliver decisions")
+
+	if l.cfg.DeadLetterPath == "" {
+		return err
+	}
+
+	if dlErr := l.deadLetter(batch); dlErr != nil {
+		return errors.Wrap(dlErr, "failed to write dead letter file")
+	}
+
+	return nil
+}
+
+func (l *httpLogger) Shutdown() {
+	l.cancel()
+	l.client.CloseIdleConnections()
+}
+
+func (l *httpLogger) encode(batch []*api.Decision) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	if l.cfg.Format == FormatJSON {
+		buf.WriteByte('[')
+	}
+
+	for i, d := range batch {
+		b, err := l.marshal(d)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 && l.cfg.Format == FormatJSON {
+			buf.WriteByte(',')
+		}
+		buf.Write(b)
+		if l.cfg.Format == FormatNDJSON {
+			buf.WriteByte('\n')
+		}
+	}
+
+	if l.cfg.Format == FormatJSON {
+		buf.WriteByte(']')
+	}
+
+	if !l.cfg.Gzip {
+		return buf.Bytes(), nil
+	}
+
+	zipped := new(bytes.Buffer)
+	w := gzip.NewWriter(zipped)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return nil, errors.Wrap(err, "failed to compress decisions")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to compress decisions")
+	}
+
+	return zipped.Bytes(), nil
+}
+
+// marshal encodes the decision in the configured schema.
+func (l *httpLogger) marshal(d *api.Decision) ([]byte, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	if l.cfg.Schema == decisionlog.SchemaOPA {
+		b, err = json.Marshal(decisionlog.NewOPAEvent(d))
+	} else {
+		b, err = protojson.MarshalOptions{UseProtoNames: true}.Marshal(d)
+	}
+
+	return b, errors.Wrap(err, "error marshaling decision")
+}
+
+// send posts the body, retrying with exponential backoff on transport errors,
+// 429 and 5xx responses.
+func (l *httpLogger) send(body []byte) error {
+	backoff := l.cfg.InitialBackoff
+
+	var err error
+	for attempt := 0; attempt <= *l.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-l.ctx.Done():
+				return errors.Wrap(l.ctx.Err(), "giving up delivery")
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > l.cfg.MaxBackoff {
+				backoff = l.cfg.MaxBackoff
+			}
+		}
+
+		err = l.post(body)
+		if err == nil {
+			return nil
+		}
+
+		var permanent errPermanent
+		if errors.As(err, &permanent) {
+			return err
+		}
+
+		l.logger.Debug().Err(err).Int("attempt", attempt+1).Msg("decision delivery failed")
+	}
+
+	return errors.Wrapf(err, "giving up after %d attempts", *l.cfg.MaxRetries+1)
+}
+
+func (l *httpLogger) post(body []byte) error {
+	req, err := http.NewRequestWithContext(l.ctx, http.MethodPost, l.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return errPermanent{errors.Wrap(err, "failed to create request")}
+	}
+
+	for k, v := range l.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if l.cfg.Format == FormatNDJSON {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if l.cfg.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	switch {
+	case l.cfg.Auth.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+l.cfg.Auth.BearerToken)
+	case l.cfg.Auth.Username != "":
+		req.SetBasicAuth(l.cfg.Auth.Username, l.cfg.Auth.Password)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	default:
+		return errPermanent{fmt.Errorf("unexpected status %s", resp.Status)}
+	}
+}
+
+// deadLetter appends the batch to the dead letter file, one decision per line. The
+// decisions are always written in the topaz schema, so that they can be replayed.
+func (l *httpLogger) deadLetter(batch []*api.Decision) error {
+	l.deadLetterMu.Lock()
+	defer l.deadLetterMu.Unlock()
+
+	f, err := os.OpenFile(l.cfg.DeadLetterPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, d := range batch {
+		b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(d)
+		if err != nil {
+			return errors.Wrap(err, "error marshaling decision")
+		}
+		if _, err := f.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+
+	l.logger.Warn().Int("count", len(batch)).Str("path", l.cfg.DeadLetterPath).Msg("decisions written to dead letter file")
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package http_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	httplogger "github.com/aserto-dev/topaz/decision_log/logger/http"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogBatchRetriesAndCompresses(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		zr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+
+		var decisions []map[string]interface{}
+		require.NoError(t, json.NewDecoder(zr).Decode(&decisions))
+		assert.Len(t, decisions, 2)
+	}))
+	defer srv.Close()
+
+	logger := zerolog.Nop()
+	l, err := httplogger.New(context.Background(), &httplogger.Config{
+		URL:            srv.URL,
+		Gzip:           true,
+		Auth:           httplogger.AuthConfig{BearerToken: "secret"},
+		InitialBackoff: time.Millisecond,
+	}, &logger)
+	require.NoError(t, err)
+	defer l.Shutdown()
+
+	err = l.(decisionlog.BatchDecisionLogger).LogBatch([]*api.Decision{{Id: "1"}, {Id: "2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestLogBatchDeadLetter(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	deadLetter := filepath.Join(t.TempDir(), "dead", "letter.ndjson")
+
+	logger := zerolog.Nop()
+	l, err := httplogger.New(context.Background(), &httplogger.Config{
+		URL:            srv.URL,
+		Format:         httplogger.FormatNDJSON,
+		DeadLetterPath: deadLetter,
+	}, &logger)
+	require.NoError(t, err)
+	defer l.Shutdown()
+
+	assert.NoError(t, l.Log(&api.Decision{Id: "1"}))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "client errors are not retried")
+
+	f, err := os.Open(deadLetter)
+	require.NoError(t, err)
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	require.True(t, scanner.Scan())
+	assert.JSONEq(t, `{"id":"1"}`, scanner.Text())
+}
+
+func TestLogNoRetries(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cfg, err := httplogger.Factory{}.Validate(map[string]interface{}{
+		"url":              srv.URL,
+		"max_retries":      0,
+		"dead_letter_path": filepath.Join(t.TempDir(), "letter.ndjson"),
+	})
+	require.NoError(t, err)
+
+	logger := zerolog.Nop()
+	l, err := httplogger.Factory{}.New(context.Background(), cfg, &logger)
+	require.NoError(t, err)
+	defer l.Shutdown()
+
+	assert.NoError(t, l.Log(&api.Decision{Id: "1"}))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "max_retries 0 disables retries")
+}
@@ -0,0 +1,26 @@
+package nop
+
+import (
+	"context"
+
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Type is the decision_logger.type value that disables decision logging.
+const Type = "nop"
+
+type Factory struct{}
+
+func (Factory) Validate(config map[string]interface{}) (interface{}, error) {
+	if len(config) > 0 {
+		return nil, errors.New("the nop decision logger does not take any configuration")
+	}
+
+	return nil, nil
+}
+
+func (Factory) New(ctx context.Context, config interface{}, logger *zerolog.Logger) (decisionlog.DecisionLogger, error) {
+	return New(ctx, logger)
+}
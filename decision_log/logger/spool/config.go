@@ -0,0 +1,83 @@
+package spool
+
+import (
+	"time"
+
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	// Dir holds the spool segments and the acknowledged offset.
+	Dir string `json:"dir"`
+	// MaxSizeMB bounds the size of the spool, the oldest segments are dropped above it.
+	MaxSizeMB int `json:"max_size_mb"`
+	// SegmentSizeMB is the size above which a new segment is started.
+	SegmentSizeMB int `json:"segment_size_mb"`
+	// Sync flushes every write to disk before it is acknowledged to the caller.
+	Sync bool `json:"sync"`
+	// BatchSize is the maximum number of decisions forwarded at once.
+	BatchSize      int           `json:"batch_size"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+	// Collector is the decision logger decisions are forwarded to.
+	Collector CollectorConfig `json:"collector"`
+}
+
+type CollectorConfig struct {
+	Type   string                 `json:"type"`
+	Config map[string]interface{} `json:"config"`
+}
+
+func (cfg *Config) SetDefaults() {
+	if cfg.MaxSizeMB == 0 {
+		cfg.MaxSizeMB = 1024
+	}
+	if cfg.SegmentSizeMB == 0 {
+		cfg.SegmentSizeMB = 16
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.InitialBackoff == 0 {
+		cfg.InitialBackoff = time.Second
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = time.Minute
+	}
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Dir == "" {
+		return errors.New("dir not set")
+	}
+
+	if cfg.MaxSizeMB < 0 || cfg.SegmentSizeMB < 0 || cfg.BatchSize < 0 || cfg.InitialBackoff < 0 || cfg.MaxBackoff < 0 {
+		return errors.New("max_size_mb, segment_size_mb, batch_size, initial_backoff and max_backoff must be positive")
+	}
+
+	if cfg.SegmentSizeMB > cfg.MaxSizeMB {
+		return errors.New("segment_size_mb must not be larger than max_size_mb")
+	}
+
+	switch cfg.Collector.Type {
+	case "":
+		return errors.New("collector.type not set")
+	case Type:
+		return errors.New("a spool cannot forward to another spool")
+	}
+
+	// undelivered decisions stay in the spool, the collector must report them as failed.
+	if _, ok := cfg.Collector.Config["dead_letter_path"]; ok {
+		return errors.New("collector.config.dead_letter_path is not supported, undelivered decisions are kept in the spool")
+	}
+
+	return errors.Wrap(decisionlog.Validate(cfg.Collector.decisionLoggerConfig()), "invalid collector")
+}
+
+func (c *CollectorConfig) decisionLoggerConfig() *decisionlog.Config {
+	return &decisionlog.Config{
+		Type:   c.Type,
+		Config: c.Config,
+	}
+}
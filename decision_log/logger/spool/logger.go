@@ -0,0 +1,555 @@
+package spool
+
+import (
+	"context"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	spoolPending = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "topaz",
+		Subsystem: "decision_log",
+		Name:      "spool_pending",
+		Help:      "Number of spooled decisions not yet acknowledged by the collector.",
+	}, []string{"dir"})
+	spoolBytes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "topaz",
+		Subsystem: "decision_log",
+		Name:      "spool_bytes",
+		Help:      "Size of the decision log spool.",
+	}, []string{"dir"})
+	spoolDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "topaz",
+		Subsystem: "decision_log",
+		Name:      "spool_dropped_total",
+		Help:      "Number of spooled decisions dropped before they were forwarded, because the spool was full or damaged.",
+	}, []string{"dir"})
+)
+
+// Spool writes every decision to an append-only segment directory and forwards the
+// segments to the collector in the background. The offset of the last decision
+// acknowledged by the collector is persisted, so forwarding resumes from it after a
+// restart. Decisions are delivered at least once: a batch that was delivered but not
+// yet acknowledged when Topaz stopped is delivered again.
+//
+// While the collector is unreachable decisions accumulate in the spool, up to
+// max_size_mb, above which the oldest segments are dropped.
+type Spool struct {
+	cfg       *Config
+	collector decisionlog.DecisionLogger
+	logger    *zerolog.Logger
+
+	mu       sync.Mutex
+	segments []*segment
+	// file is the last segment, open for writing.
+	file *os.File
+	// next is the offset of the next decision written.
+	next uint64
+	// acked is the offset of the first decision not acknowledged by the collector.
+	acked  uint64
+	size   int64
+	cursor cursor
+	closed bool
+
+	notify chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+var _ decisionlog.BatchDecisionLogger = (*Spool)(nil)
+
+func New(ctx context.Context, cfg *Config, logger *zerolog.Logger) (*Spool, error) {
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "failed to create spool directory")
+	}
+
+	collector, err := decisionlog.New(ctx, cfg.Collector.decisionLoggerConfig(), logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create collector")
+	}
+
+	newLogger := logger.With().Str("component", "decision-log-spool").Str("dir", cfg.Dir).Logger()
+
+	s := &Spool{
+		cfg:       cfg,
+		collector: collector,
+		logger:    &newLogger,
+		notify:    make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	if err := s.open(); err != nil {
+		collector.Shutdown()
+		return nil, err
+	}
+
+	if pending := s.next - s.acked; pending > 0 {
+		s.logger.Info().Uint64("pending", pending).Msg("resuming forwarding of spooled decisions")
+	}
+
+	go s.forward()
+
+	return s, nil
+}
+
+func (s *Spool) Log(d *api.Decision) error {
+	return s.LogBatch([]*api.Decision{d})
+}
+
+// LogBatch appends the decisions to the spool. They are forwarded to the collector later.
+func (s *Spool) LogBatch(batch []*api.Decision) error {
+	frames := make([][]byte, 0, len(batch))
+	for _, d := range batch {
+		frame, err := encodeRecord(d)
+		if err != nil {
+			return err
+		}
+		frames = append(frames, frame)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return decisionlog.ErrLoggerClosed
+	}
+
+	for _, frame := range frames {
+		if err := s.write(frame); err != nil {
+			return err
+		}
+	}
+
+	if s.cfg.Sync && s.file != nil {
+		if err := s.file.Sync(); err != nil {
+			return errors.Wrap(err, "failed to sync spool segment")
+		}
+	}
+
+	s.enforceSizeLimit()
+	s.updateMetrics()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Shutdown stops forwarding. Decisions not acknowledged yet stay in the spool and are
+// forwarded after the next start.
+func (s *Spool) Shutdown() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	// interrupts a delivery in progress.
+	s.collector.Shutdown()
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cursor.close()
+	if s.file != nil {
+		if err := s.file.Sync(); err != nil {
+			s.logger.Error().Err(err).Msg("failed to sync spool segment")
+		}
+		_ = s.file.Close()
+		s.file = nil
+	}
+}
+
+// Pending returns the number of decisions not acknowledged by the collector yet.
+func (s *Spool) Pending() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next - s.acked
+}
+
+// open loads the segments and the acknowledged offset left by the previous run.
+func (s *Spool) open() error {
+	segments, err := listSegments(s.cfg.Dir)
+	if err != nil {
+		return err
+	}
+
+	acked, ok, err := readAck(s.cfg.Dir)
+	if err != nil {
+		return err
+	}
+
+	s.segments = segments
+	s.next = acked
+
+	if n := len(segments); n > 0 {
+		count, err := recoverSegment(segments[n-1])
+		if err != nil {
+			return err
+		}
+		s.next = segments[n-1].start + count
+
+		for _, seg := range segments {
+			s.size += seg.size
+		}
+	}
+
+	first := s.next
+	if len(segments) > 0 {
+		first = segments[0].start
+	}
+
+	switch {
+	case !ok || acked < first:
+		s.acked = first
+	case acked > s.next:
+		s.acked = s.next
+	default:
+		s.acked = acked
+	}
+
+	s.removeAcked()
+	s.updateMetrics()
+
+	return nil
+}
+
+// write appends a framed record to the last segment, starting a new segment when it is full.
+func (s *Spool) write(frame []byte) error {
+	if err := s.ensureSegment(); err != nil {
+		return err
+	}
+
+	active := s.segments[len(s.segments)-1]
+
+	n, err := s.file.Write(frame)
+	if err != nil {
+		// a partially written record would hide the records written after it.
+		if n > 0 {
+			_ = s.file.Truncate(active.size)
+		}
+		_ = s.file.Close()
+		s.file = nil
+		return errors.Wrap(err, "failed to write to spool")
+	}
+
+	active.size += int64(n)
+	s.size += int64(n)
+	s.next++
+
+	return nil
+}
+
+func (s *Spool) ensureSegment() error {
+	segmentSize := int64(s.cfg.SegmentSizeMB) << 20
+
+	var last *segment
+	if n := len(s.segments); n > 0 {
+		last = s.segments[n-1]
+	}
+
+	if s.file != nil {
+		if last.size < segmentSize {
+			return nil
+		}
+
+		if err := s.file.Sync(); err != nil {
+			return errors.Wrap(err, "failed to sync spool segment")
+		}
+		_ = s.file.Close()
+		s.file = nil
+	} else if last != nil && last.size < segmentSize {
+		f, err := os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return errors.Wrap(err, "failed to open spool segment")
+		}
+		s.file = f
+		return nil
+	}
+
+	seg := &segment{start: s.next, path: segmentPath(s.cfg.Dir, s.next)}
+
+	f, err := os.OpenFile(seg.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create spool segment")
+	}
+
+	s.file = f
+	s.segments = append(s.segments, seg)
+
+	return nil
+}
+
+// enforceSizeLimit drops the oldest segments while the spool is larger than max_size_mb.
+// The segment being written is never dropped.
+func (s *Spool) enforceSizeLimit() {
+	maxSize := int64(s.cfg.MaxSizeMB) << 20
+
+	var dropped uint64
+	for s.size > maxSize && len(s.segments) > 1 {
+		oldest, end := s.segments[0], s.segments[1].start
+
+		if s.acked < end {
+			dropped += end - maxOffset(s.acked, oldest.start)
+			s.acked = end
+		}
+
+		s.removeOldest()
+	}
+
+	if dropped > 0 {
+		spoolDropped.WithLabelValues(s.cfg.Dir).Add(float64(dropped))
+		s.logger.Warn().Uint64("count", dropped).Msg("spool is full, dropped the oldest decisions")
+		s.persistAck()
+	}
+}
+
+// removeAcked removes the segments whose decisions were all acknowledged.
+func (s *Spool) removeAcked() {
+	for len(s.segments) > 1 && s.segments[1].start <= s.acked {
+		s.removeOldest()
+	}
+}
+
+func (s *Spool) removeOldest() {
+	seg := s.segments[0]
+
+	if s.cursor.seg == seg {
+		s.cursor.close()
+	}
+
+	if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+		s.logger.Error().Err(err).Str("segment", seg.path).Msg("failed to remove spool segment")
+	}
+
+	s.size -= seg.size
+	s.segments = s.segments[1:]
+}
+
+func (s *Spool) persistAck() {
+	if err := writeAck(s.cfg.Dir, s.acked, s.cfg.Sync); err != nil {
+		s.logger.Error().Err(err).Msg("failed to write spool acknowledged offset")
+	}
+}
+
+func (s *Spool) updateMetrics() {
+	spoolPending.WithLabelValues(s.cfg.Dir).Set(float64(s.next - s.acked))
+	spoolBytes.WithLabelValues(s.cfg.Dir).Set(float64(s.size))
+}
+
+// forward sends the spooled decisions to the collector, retrying a failed batch with
+// exponential backoff until it is delivered.
+func (s *Spool) forward() {
+	defer close(s.done)
+
+	backoff := s.cfg.InitialBackoff
+
+	var batch []*api.Decision
+	var to uint64
+
+	for {
+		if len(batch) == 0 {
+			var err error
+			batch, to, err = s.readBatch()
+			if err != nil {
+				s.logger.Error().Err(err).Msg("failed to read spooled decisions")
+				if !s.sleep(backoff) {
+					return
+				}
+				continue
+			}
+
+			if len(batch) == 0 {
+				select {
+				case <-s.stop:
+					return
+				case <-s.notify:
+				}
+				continue
+			}
+		}
+
+		if err := s.send(batch); err != nil {
+			s.logger.Warn().Err(err).Int("count", len(batch)).Dur("retry_in", backoff).Msg("failed to forward decisions, keeping them in the spool")
+
+			if !s.sleep(backoff) {
+				return
+			}
+
+			backoff *= 2
+			if backoff > s.cfg.MaxBackoff {
+				backoff = s.cfg.MaxBackoff
+			}
+			continue
+		}
+
+		backoff = s.cfg.InitialBackoff
+		s.ack(to)
+		batch = nil
+	}
+}
+
+func (s *Spool) send(batch []*api.Decision) error {
+	if b, ok := s.collector.(decisionlog.BatchDecisionLogger); ok {
+		return b.LogBatch(batch)
+	}
+
+	for _, d := range batch {
+		if err := s.collector.Log(d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sleep waits for d, it returns false when the spool is shut down in the meantime.
+func (s *Spool) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-s.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// ack records that the collector acknowledged the decisions before offset to.
+func (s *Spool) ack(to uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if to > s.acked {
+		s.acked = to
+		s.persistAck()
+	}
+
+	s.removeAcked()
+	s.updateMetrics()
+}
+
+// readBatch reads up to batch_size decisions following the ones read last, or following
+// the acknowledged offset after a restart or after the spool dropped unread segments.
+// It returns the decisions and the offset following them.
+func (s *Spool) readBatch() ([]*api.Decision, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.acked >= s.next {
+		return nil, s.acked, nil
+	}
+
+	if s.cursor.file == nil || s.cursor.offset < s.acked {
+		if err := s.seek(s.acked); err != nil {
+			return nil, s.acked, err
+		}
+	}
+
+	var batch []*api.Decision
+	for len(batch) < s.cfg.BatchSize && s.cursor.offset < s.next {
+		if next := s.following(s.cursor.seg); next != nil && s.cursor.offset >= next.start {
+			if err := s.cursor.open(next); err != nil {
+				return batch, s.cursor.offset, err
+			}
+		}
+
+		b, _, err := readRecord(s.cursor.reader)
+		if err != nil {
+			if err := s.skipDamaged(err); err != nil {
+				return batch, s.cursor.offset, err
+			}
+			continue
+		}
+
+		d := &api.Decision{}
+		if err := proto.Unmarshal(b, d); err != nil {
+			spoolDropped.WithLabelValues(s.cfg.Dir).Inc()
+			s.logger.Error().Err(err).Uint64("offset", s.cursor.offset).Msg("dropped unreadable spooled decision")
+			s.cursor.offset++
+			continue
+		}
+
+		batch = append(batch, d)
+		s.cursor.offset++
+	}
+
+	return batch, s.cursor.offset, nil
+}
+
+// skipDamaged moves the cursor past the rest of a damaged segment.
+func (s *Spool) skipDamaged(readErr error) error {
+	next := s.following(s.cursor.seg)
+	if next == nil {
+		// the segment being written only holds complete records.
+		return errors.Wrap(readErr, "failed to read spool segment")
+	}
+
+	dropped := next.start - s.cursor.offset
+	spoolDropped.WithLabelValues(s.cfg.Dir).Add(float64(dropped))
+	s.logger.Error().Err(readErr).Str("segment", s.cursor.seg.path).Uint64("count", dropped).Msg("dropped the rest of a damaged spool segment")
+
+	return s.cursor.open(next)
+}
+
+// seek positions the cursor at offset.
+func (s *Spool) seek(offset uint64) error {
+	i := sort.Search(len(s.segments), func(i int) bool { return s.segments[i].start > offset }) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	if err := s.cursor.open(s.segments[i]); err != nil {
+		return err
+	}
+
+	for s.cursor.offset < offset {
+		if _, _, err := readRecord(s.cursor.reader); err != nil {
+			if err == io.EOF {
+				return errors.Wrap(io.ErrUnexpectedEOF, "spool segment is shorter than expected")
+			}
+			return s.skipDamaged(err)
+		}
+		s.cursor.offset++
+	}
+
+	return nil
+}
+
+// following returns the segment after seg, or nil when seg is the last one.
+func (s *Spool) following(seg *segment) *segment {
+	for i, sg := range s.segments {
+		if sg == seg && i+1 < len(s.segments) {
+			return s.segments[i+1]
+		}
+	}
+	return nil
+}
+
+func maxOffset(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
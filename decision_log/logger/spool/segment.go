@@ -0,0 +1,222 @@
+package spool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	segmentExt  = ".seg"
+	ackFileName = "ack"
+	// frameHeaderSize is the size of the length and CRC-32 that precede every record.
+	frameHeaderSize = 8
+	// maxRecordSize guards against allocating huge buffers for a damaged length.
+	maxRecordSize = 64 << 20
+)
+
+var errCorrupt = errors.New("damaged spool record")
+
+// segment is a spool file holding consecutive records. Its name is the offset of its
+// first record, zero padded so that segments sort by name.
+type segment struct {
+	start uint64
+	path  string
+	size  int64
+}
+
+func segmentPath(dir string, start uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", start, segmentExt))
+}
+
+// listSegments returns the segments in dir, oldest first.
+func listSegments(dir string) ([]*segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list spool segments")
+	}
+
+	var segments []*segment
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), segmentExt) {
+			continue
+		}
+
+		start, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		fi, err := e.Info()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list spool segments")
+		}
+
+		segments = append(segments, &segment{start: start, path: filepath.Join(dir, e.Name()), size: fi.Size()})
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].start < segments[j].start })
+
+	return segments, nil
+}
+
+// encodeRecord frames the protobuf encoding of d with its length and CRC-32.
+func encodeRecord(d *api.Decision) ([]byte, error) {
+	b, err := proto.Marshal(d)
+	if err != nil {
+		return nil, errors.Wrap(err, "error marshaling decision")
+	}
+
+	frame := make([]byte, frameHeaderSize+len(b))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(b)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(b))
+	copy(frame[frameHeaderSize:], b)
+
+	return frame, nil
+}
+
+// readRecord reads the next record. It returns io.EOF at the end of the segment, and
+// io.ErrUnexpectedEOF or errCorrupt for a partially written or damaged record.
+func readRecord(r io.Reader) ([]byte, int64, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, 0, err
+	}
+
+	n := binary.BigEndian.Uint32(header[0:4])
+	if n > maxRecordSize {
+		return nil, 0, errCorrupt
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+
+	if crc32.ChecksumIEEE(b) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errCorrupt
+	}
+
+	return b, int64(frameHeaderSize) + int64(n), nil
+}
+
+// recoverSegment returns the number of records in the segment. A partially written
+// record, left at the end of the segment by a crash, is truncated.
+func recoverSegment(seg *segment) (uint64, error) {
+	f, err := os.OpenFile(seg.path, os.O_RDWR, 0)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to open spool segment")
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+
+	var count uint64
+	var valid int64
+	for {
+		_, n, err := readRecord(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if err := f.Truncate(valid); err != nil {
+				return 0, errors.Wrap(err, "failed to truncate spool segment")
+			}
+			break
+		}
+
+		count++
+		valid += n
+	}
+
+	seg.size = valid
+	return count, nil
+}
+
+// cursor reads records from the segments, from the oldest unacknowledged one onwards.
+type cursor struct {
+	seg    *segment
+	file   *os.File
+	reader *bufio.Reader
+	// offset is the offset of the next record read.
+	offset uint64
+}
+
+func (c *cursor) open(seg *segment) error {
+	c.close()
+
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open spool segment")
+	}
+
+	c.seg, c.file, c.reader, c.offset = seg, f, bufio.NewReader(f), seg.start
+	return nil
+}
+
+func (c *cursor) close() {
+	if c.file != nil {
+		_ = c.file.Close()
+	}
+	c.seg, c.file, c.reader = nil, nil, nil
+}
+
+func readAck(dir string) (uint64, bool, error) {
+	b, err := os.ReadFile(filepath.Join(dir, ackFileName))
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to read spool acknowledged offset")
+	}
+
+	offset, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "invalid spool acknowledged offset")
+	}
+
+	return offset, true, nil
+}
+
+// writeAck replaces the acknowledged offset, through a rename so that a crash leaves
+// either the previous or the new offset.
+func writeAck(dir string, offset uint64, sync bool) error {
+	tmp := filepath.Join(dir, ackFileName+".tmp")
+
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(strconv.FormatUint(offset, 10) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+
+	if sync {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(dir, ackFileName))
+}
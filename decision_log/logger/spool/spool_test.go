@@ -0,0 +1,247 @@
+package spool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const collectorType = "spool-test-collector"
+
+// collector records the decisions it receives, and fails while down.
+type collector struct {
+	mu       sync.Mutex
+	down     bool
+	received []string
+}
+
+func (c *collector) LogBatch(batch []*api.Decision) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.down {
+		return errors.New("collector unreachable")
+	}
+
+	for _, d := range batch {
+		c.received = append(c.received, d.Id)
+	}
+	return nil
+}
+
+func (c *collector) Log(d *api.Decision) error {
+	return c.LogBatch([]*api.Decision{d})
+}
+
+func (c *collector) Shutdown() {}
+
+func (c *collector) setDown(down bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.down = down
+}
+
+func (c *collector) ids() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string{}, c.received...)
+}
+
+var (
+	collectorsMu sync.Mutex
+	collectors   = map[string]*collector{}
+)
+
+type collectorFactory struct{}
+
+func (collectorFactory) Validate(config map[string]interface{}) (interface{}, error) {
+	return config["name"], nil
+}
+
+func (collectorFactory) New(ctx context.Context, config interface{}, logger *zerolog.Logger) (decisionlog.DecisionLogger, error) {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+	return collectors[config.(string)], nil
+}
+
+func init() { // nolint: gochecknoinits
+	decisionlog.Register(collectorType, collectorFactory{})
+}
+
+func newCollector(t *testing.T) *collector {
+	c := &collector{}
+
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+	collectors[t.Name()] = c
+
+	return c
+}
+
+func newSpool(t *testing.T, cfg *Config) *Spool {
+	logger := zerolog.Nop()
+
+	cfg.InitialBackoff = 10 * time.Millisecond
+	cfg.MaxBackoff = 20 * time.Millisecond
+	cfg.Collector = CollectorConfig{Type: collectorType, Config: map[string]interface{}{"name": t.Name()}}
+
+	s, err := New(context.Background(), cfg, &logger)
+	require.NoError(t, err)
+
+	return s
+}
+
+func logN(t *testing.T, s *Spool, from, to int) []string {
+	var ids []string
+	for i := from; i < to; i++ {
+		id := fmt.Sprintf("%05d", i)
+		require.NoError(t, s.Log(&api.Decision{Id: id, Path: "peoplefinder.GET.users"}))
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func waitDelivered(t *testing.T, s *Spool) {
+	assert.Eventually(t, func() bool { return s.Pending() == 0 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	segments, err := listSegments(dir)
+	require.NoError(t, err)
+
+	var files []string
+	for _, seg := range segments {
+		files = append(files, seg.path)
+	}
+	return files
+}
+
+func TestForward(t *testing.T) {
+	c := newCollector(t)
+	s := newSpool(t, &Config{Dir: t.TempDir()})
+	defer s.Shutdown()
+
+	expected := logN(t, s, 0, 250)
+
+	waitDelivered(t, s)
+	assert.Equal(t, expected, c.ids())
+}
+
+func TestForwardAfterOutage(t *testing.T) {
+	c := newCollector(t)
+	c.setDown(true)
+
+	s := newSpool(t, &Config{Dir: t.TempDir()})
+	defer s.Shutdown()
+
+	expected := logN(t, s, 0, 50)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, c.ids())
+	assert.Equal(t, uint64(50), s.Pending())
+
+	c.setDown(false)
+	expected = append(expected, logN(t, s, 50, 60)...)
+
+	waitDelivered(t, s)
+	assert.Equal(t, expected, c.ids())
+}
+
+func TestResumeAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	c := newCollector(t)
+
+	s := newSpool(t, &Config{Dir: dir})
+	delivered := logN(t, s, 0, 10)
+	waitDelivered(t, s)
+
+	c.setDown(true)
+	pending := logN(t, s, 10, 30)
+	s.Shutdown()
+
+	c.setDown(false)
+	s = newSpool(t, &Config{Dir: dir})
+	assert.Equal(t, uint64(20), s.Pending())
+	waitDelivered(t, s)
+	s.Shutdown()
+
+	assert.Equal(t, append(delivered, pending...), c.ids())
+
+	// the acknowledged offset survives the restart, delivered decisions are not sent again.
+	s = newSpool(t, &Config{Dir: dir})
+	defer s.Shutdown()
+
+	more := logN(t, s, 30, 35)
+	waitDelivered(t, s)
+	assert.Equal(t, append(append(delivered, pending...), more...), c.ids())
+}
+
+func TestSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	c := newCollector(t)
+	c.setDown(true)
+
+	s := newSpool(t, &Config{Dir: dir, MaxSizeMB: 2, SegmentSizeMB: 1})
+	defer s.Shutdown()
+
+	padding := strings.Repeat("x", 1000)
+	for i := 0; i < 5000; i++ {
+		require.NoError(t, s.Log(&api.Decision{Id: fmt.Sprintf("%05d", i), Annotations: map[string]string{"padding": padding}}))
+	}
+
+	s.mu.Lock()
+	size := s.size
+	s.mu.Unlock()
+	assert.LessOrEqual(t, size, int64(2<<20))
+
+	pending := s.Pending()
+	assert.Less(t, pending, uint64(5000))
+
+	c.setDown(false)
+	waitDelivered(t, s)
+
+	// the newest decisions are kept, the batch read before the outage is delivered too.
+	ids := c.ids()
+	require.GreaterOrEqual(t, len(ids), int(pending))
+	assert.Equal(t, fmt.Sprintf("%05d", 5000-pending), ids[len(ids)-int(pending)])
+	assert.Equal(t, "04999", ids[len(ids)-1])
+	assert.Len(t, segmentFiles(t, dir), 1)
+}
+
+func TestRecoverPartialRecord(t *testing.T) {
+	dir := t.TempDir()
+	c := newCollector(t)
+	c.setDown(true)
+
+	s := newSpool(t, &Config{Dir: dir})
+	expected := logN(t, s, 0, 5)
+	s.Shutdown()
+
+	// a crash in the middle of a write leaves a partial record behind.
+	files := segmentFiles(t, dir)
+	require.Len(t, files, 1)
+	f, err := os.OpenFile(files[0], os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 1, 0, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	c.setDown(false)
+	s = newSpool(t, &Config{Dir: dir})
+	defer s.Shutdown()
+
+	expected = append(expected, logN(t, s, 5, 8)...)
+	waitDelivered(t, s)
+	assert.Equal(t, expected, c.ids())
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	// Path is the bbolt database file.
+	Path string `json:"path"`
+	// MaxAge is how long decisions are kept, 7 days when 0. Decisions are kept regardless of their age when negative.
+	MaxAge time.Duration `json:"max_age"`
+	// MaxSizeMB bounds the space used by the stored decisions, there is no limit when 0.
+	MaxSizeMB int `json:"max_size_mb"`
+	// RetentionInterval is how often expired decisions are removed.
+	RetentionInterval time.Duration `json:"retention_interval"`
+}
+
+func (cfg *Config) SetDefaults() {
+	if cfg.MaxAge == 0 {
+		cfg.MaxAge = 7 * 24 * time.Hour
+	}
+	if cfg.RetentionInterval == 0 {
+		cfg.RetentionInterval = time.Minute
+	}
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Path == "" {
+		return errors.New("path not set")
+	}
+
+	if cfg.MaxSizeMB < 0 || cfg.RetentionInterval < 0 {
+		return errors.New("max_size_mb and retention_interval must be positive")
+	}
+
+	return nil
+}
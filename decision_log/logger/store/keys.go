@@ -0,0 +1,99 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+)
+
+// Decisions are stored in the decisions bucket, keyed by their timestamp followed by
+// their id, so that keys sort chronologically. Each index bucket holds one empty entry
+// per decision, keyed by the indexed value, a separator and the decision key.
+var (
+	decisionsBucket = []byte("decisions")
+	userIndex       = []byte("idx_user")
+	pathIndex       = []byte("idx_path")
+	outcomeIndex    = []byte("idx_outcome")
+
+	indexes = [][]byte{userIndex, pathIndex, outcomeIndex}
+)
+
+const (
+	separator = 0x00
+	tsLen     = 8
+)
+
+// decisionKey returns the key of the decision in the decisions bucket.
+func decisionKey(d *api.Decision) []byte {
+	var nanos int64
+	if d.Timestamp != nil {
+		nanos = d.Timestamp.AsTime().UnixNano()
+	}
+
+	key := tsPrefix(nanos)
+	return append(key, d.Id...)
+}
+
+func tsPrefix(nanos int64) []byte {
+	if nanos < 0 {
+		nanos = 0
+	}
+
+	key := make([]byte, tsLen, tsLen+36)
+	binary.BigEndian.PutUint64(key, uint64(nanos))
+	return key
+}
+
+func timeOf(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[:tsLen])))
+}
+
+// indexPrefix returns the common prefix of all index entries of a value.
+func indexPrefix(value string) []byte {
+	prefix := make([]byte, 0, len(value)+1)
+	prefix = append(prefix, value...)
+	return append(prefix, separator)
+}
+
+func indexKey(value string, key []byte) []byte {
+	return append(indexPrefix(value), key...)
+}
+
+// indexValues returns the values indexed for the decision, by index bucket.
+func indexValues(d *api.Decision) map[string][]string {
+	var users []string
+	if id := d.GetUser().GetId(); id != "" {
+		users = append(users, id)
+	}
+	if email := strings.ToLower(d.GetUser().GetEmail()); email != "" && email != d.GetUser().GetId() {
+		users = append(users, email)
+	}
+
+	return map[string][]string{
+		string(userIndex):    users,
+		string(pathIndex):    {d.Path},
+		string(outcomeIndex): {decisionlog.Outcome(d)},
+	}
+}
+
+// rangeKeys returns the lower and upper bounds of the keys with the given prefix that
+// fall in the [from, to) time range.
+func rangeKeys(prefix []byte, from, to time.Time) (lower, upper []byte) {
+	lower = append([]byte{}, prefix...)
+	if !from.IsZero() {
+		lower = append(lower, tsPrefix(from.UnixNano())...)
+	}
+
+	upper = append([]byte{}, prefix...)
+	if to.IsZero() {
+		upper = append(upper, bytes.Repeat([]byte{0xff}, tsLen+1)...)
+	} else {
+		upper = append(upper, tsPrefix(to.UnixNano())...)
+	}
+
+	return lower, upper
+}
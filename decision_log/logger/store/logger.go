@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+)
+
+// Store keeps decisions in a local bbolt database, indexed by timestamp, user, path and
+// outcome, and removes them once they exceed the configured age or size.
+type Store struct {
+	cfg    *Config
+	db     *bolt.DB
+	logger *zerolog.Logger
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+var (
+	_ decisionlog.BatchDecisionLogger = (*Store)(nil)
+	_ decisionlog.DecisionReader      = (*Store)(nil)
+)
+
+func New(ctx context.Context, cfg *Config, logger *zerolog.Logger) (*Store, error) {
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o700); err != nil {
+		return nil, errors.Wrap(err, "failed to create decision store directory")
+	}
+
+	db, err := bolt.Open(cfg.Path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open decision store [%s]", cfg.Path)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range append([][]byte{decisionsBucket}, indexes...) {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to initialize decision store")
+	}
+
+	newLogger := logger.With().Str("component", "decision-log-store").Str("path", cfg.Path).Logger()
+
+	s := &Store{
+		cfg:    cfg,
+		db:     db,
+		logger: &newLogger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go s.retain()
+
+	return s, nil
+}
+
+func (s *Store) Log(d *api.Decision) error {
+	return s.LogBatch([]*api.Decision{d})
+}
+
+// LogBatch stores the decisions and their index entries in a single transaction.
+func (s *Store) LogBatch(batch []*api.Decision) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		decisions := tx.Bucket(decisionsBucket)
+
+		for _, d := range batch {
+			value, err := proto.Marshal(d)
+			if err != nil {
+				return errors.Wrap(err, "error marshaling decision")
+			}
+
+			key := decisionKey(d)
+			if err := decisions.Put(key, value); err != nil {
+				return err
+			}
+
+			for index, values := range indexValues(d) {
+				b := tx.Bucket([]byte(index))
+				for _, v := range values {
+					if err := b.Put(indexKey(v, key), nil); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Shutdown stops the retention loop and closes the database.
+func (s *Store) Shutdown() {
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+
+		if err := s.db.Close(); err != nil {
+			s.logger.Error().Err(err).Msg("failed to close decision store")
+		}
+	})
+}
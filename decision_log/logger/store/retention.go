@@ -0,0 +1,163 @@
+package store
+
+import (
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// purgeBatchSize is the number of decisions removed per transaction.
+	purgeBatchSize = 1000
+	// sizeLowWatermark is the fraction of max_size_mb the store is trimmed down to once
+	// it exceeds the limit, so that trimming does not run on every new decision.
+	sizeLowWatermark = 0.9
+)
+
+func (s *Store) retain() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.RetentionInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.enforceRetention(time.Now()); err != nil {
+			s.logger.Error().Err(err).Msg("failed to enforce decision retention")
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// enforceRetention removes the decisions older than max_age, then the oldest decisions
+// until the store is back under max_size_mb.
+//
+// bbolt does not shrink its file, the space of removed decisions is reused for new ones.
+func (s *Store) enforceRetention(now time.Time) error {
+	if s.cfg.MaxAge > 0 {
+		cutoff := now.Add(-s.cfg.MaxAge)
+
+		n, err := s.purge(func(key, value []byte) bool {
+			return timeOf(key).Before(cutoff)
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to remove expired decisions")
+		}
+		if n > 0 {
+			s.logger.Debug().Int("count", n).Time("cutoff", cutoff).Msg("removed expired decisions")
+		}
+	}
+
+	if s.cfg.MaxSizeMB > 0 {
+		size, err := s.size()
+		if err != nil {
+			return err
+		}
+
+		maxSize := int64(s.cfg.MaxSizeMB) << 20
+		if size <= maxSize {
+			return nil
+		}
+
+		excess := size - int64(float64(maxSize)*sizeLowWatermark)
+		var freed int64
+
+		n, err := s.purge(func(key, value []byte) bool {
+			if freed >= excess {
+				return false
+			}
+			// the decision and its index entries, which all end with the decision key.
+			freed += int64(len(value) + 4*len(key))
+			return true
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to trim decision store")
+		}
+		if n > 0 {
+			s.logger.Debug().Int("count", n).Int64("size", size).Msg("removed oldest decisions over the size limit")
+		}
+	}
+
+	return nil
+}
+
+// purge removes decisions from the oldest one onwards, as long as remove returns true.
+func (s *Store) purge(remove func(key, value []byte) bool) (int, error) {
+	total := 0
+
+	for {
+		n, more := 0, false
+
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			c := tx.Bucket(decisionsBucket).Cursor()
+
+			for k, v := c.First(); k != nil; k, v = c.First() {
+				if n == purgeBatchSize {
+					more = true
+					return nil
+				}
+
+				if !remove(k, v) {
+					return nil
+				}
+
+				if err := deleteIndexEntries(tx, k, v); err != nil {
+					return err
+				}
+
+				if err := c.Delete(); err != nil {
+					return err
+				}
+				n++
+			}
+
+			return nil
+		})
+
+		total += n
+		if err != nil || !more {
+			return total, err
+		}
+	}
+}
+
+func deleteIndexEntries(tx *bolt.Tx, key, value []byte) error {
+	d := &api.Decision{}
+	if err := proto.Unmarshal(value, d); err != nil {
+		// without the decision its index entries cannot be found, searches skip them.
+		return nil
+	}
+
+	for index, values := range indexValues(d) {
+		b := tx.Bucket([]byte(index))
+		for _, v := range values {
+			if err := b.Delete(indexKey(v, key)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// size returns the number of bytes used by the decisions and their indexes.
+func (s *Store) size() (int64, error) {
+	var size int64
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		for _, name := range append([][]byte{decisionsBucket}, indexes...) {
+			stats := tx.Bucket(name).Stats()
+			size += int64(stats.BranchInuse + stats.LeafInuse)
+		}
+		return nil
+	})
+
+	return size, err
+}
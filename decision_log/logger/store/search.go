@@ -0,0 +1,146 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
+)
+
+// globChars are the characters that make a query path a pattern rather than a literal path.
+const globChars = "*?[{\\"
+
+// Search returns the decisions matching the query, in the order of the query. The most
+// selective index available for the query is scanned from the newest entry backwards, or
+// from the oldest entry forwards when the oldest decisions come first.
+func (s *Store) Search(ctx context.Context, q *decisionlog.Query) ([]*api.Decision, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	var result []*api.Decision
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		scan := func(index []byte, value string) error {
+			matches, err := s.scan(ctx, tx, index, value, q)
+			result = append(result, matches...)
+			return err
+		}
+
+		switch {
+		case q.User != "":
+			// users are indexed both by id and by email.
+			if err := scan(userIndex, q.User); err != nil {
+				return err
+			}
+			if email := strings.ToLower(q.User); email != q.User {
+				return scan(userIndex, email)
+			}
+			return nil
+		case q.Path != "" && !strings.ContainsAny(q.Path, globChars):
+			return scan(pathIndex, q.Path)
+		case q.Outcome != "":
+			return scan(outcomeIndex, q.Outcome)
+		default:
+			return scan(nil, "")
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return q.Page(dedupe(result)), nil
+}
+
+// scan walks the decisions bucket, or the entries of value in the given index, in the
+// queried time range in the order of the query, until the query limit is reached.
+func (s *Store) scan(ctx context.Context, tx *bolt.Tx, index []byte, value string, q *decisionlog.Query) ([]*api.Decision, error) {
+	decisions := tx.Bucket(decisionsBucket)
+
+	b := decisions
+	var prefix []byte
+	if index != nil {
+		b = tx.Bucket(index)
+		prefix = indexPrefix(value)
+	}
+
+	from, to := q.Range()
+	lower, upper := rangeKeys(prefix, from, to)
+
+	var result []*api.Decision
+
+	c := b.Cursor()
+	k, v, next, inRange := newestFirst(c, lower, upper)
+	if q.OldestFirst {
+		k, v, next, inRange = oldestFirst(c, lower, upper)
+	}
+
+	for n := 0; k != nil && bytes.HasPrefix(k, prefix) && inRange(k); k, v = next() {
+		if n++; n%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		if index != nil {
+			v = decisions.Get(k[len(prefix):])
+			if v == nil {
+				continue
+			}
+		}
+
+		d := &api.Decision{}
+		if err := proto.Unmarshal(v, d); err != nil {
+			s.logger.Warn().Err(err).Msg("skipping unreadable decision")
+			continue
+		}
+
+		if !q.Match(d) {
+			continue
+		}
+
+		result = append(result, d)
+		if len(result) >= q.Limit {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// newestFirst positions the cursor on the last key before upper, and walks backwards down to lower.
+func newestFirst(c *bolt.Cursor, lower, upper []byte) (k, v []byte, next func() ([]byte, []byte), inRange func([]byte) bool) {
+	k, v = c.Seek(upper)
+	if k == nil {
+		k, v = c.Last()
+	} else {
+		k, v = c.Prev()
+	}
+
+	return k, v, c.Prev, func(k []byte) bool { return bytes.Compare(k, lower) >= 0 }
+}
+
+// oldestFirst positions the cursor on the first key at or after lower, and walks forwards up to upper.
+func oldestFirst(c *bolt.Cursor, lower, upper []byte) (k, v []byte, next func() ([]byte, []byte), inRange func([]byte) bool) {
+	k, v = c.Seek(lower)
+	return k, v, c.Next, func(k []byte) bool { return bytes.Compare(k, upper) < 0 }
+}
+
+func dedupe(decisions []*api.Decision) []*api.Decision {
+	seen := make(map[string]bool, len(decisions))
+	result := decisions[:0]
+
+	for _, d := range decisions {
+		if seen[d.Id] {
+			continue
+		}
+		seen[d.Id] = true
+		result = append(result, d)
+	}
+
+	return result
+}
@@ -0,0 +1,176 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/decisiontest"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStore(t *testing.T, cfg *Config) *Store {
+	logger := zerolog.Nop()
+
+	cfg.Path = filepath.Join(t.TempDir(), "decisions.db")
+	cfg.RetentionInterval = time.Hour
+
+	s, err := New(context.Background(), cfg, &logger)
+	require.NoError(t, err)
+	t.Cleanup(s.Shutdown)
+
+	return s
+}
+
+func TestSearch(t *testing.T) {
+	s := newStore(t, &Config{MaxAge: -1})
+
+	require.NoError(t, s.LogBatch([]*api.Decision{
+		decisiontest.Decision("1", 1, "alice@acmecorp.com", "peoplefinder.GET.users", true),
+		decisiontest.Decision("2", 2, "bob@acmecorp.com", "peoplefinder.GET.users", false),
+		decisiontest.Decision("3", 42, "Alice@acmecorp.com", "peoplefinder.POST.users", false),
+	}))
+	require.NoError(t, s.Log(decisiontest.Decision("4", 43, "alice@acmecorp.com", "todo.GET.todos", true)))
+
+	tests := map[string]struct {
+		query    decisionlog.Query
+		expected []string
+	}{
+		"all newest first": {decisionlog.Query{}, []string{"4", "3", "2", "1"}},
+		"limit":            {decisionlog.Query{Limit: 2}, []string{"4", "3"}},
+		"user by email":    {decisionlog.Query{User: "alice@ACMECORP.com"}, []string{"4", "3", "1"}},
+		"user by id":       {decisionlog.Query{User: "id-bob@acmecorp.com"}, []string{"2"}},
+		"path":             {decisionlog.Query{Path: "peoplefinder.GET.users"}, []string{"2", "1"}},
+		"path glob":        {decisionlog.Query{Path: "peoplefinder.*.users"}, []string{"3", "2", "1"}},
+		"denies":           {decisionlog.Query{Outcome: decisionlog.OutcomeDeny}, []string{"3", "2"}},
+		"combined":         {decisionlog.Query{Path: "peoplefinder.GET.users", Outcome: decisionlog.OutcomeAllow}, []string{"1"}},
+		"unknown user":     {decisionlog.Query{User: "eve"}, []string{}},
+		"time range": {
+			decisionlog.Query{From: decisiontest.Base.Add(2 * time.Minute), To: decisiontest.Base.Add(43 * time.Minute)},
+			[]string{"3", "2"},
+		},
+		"time range on index": {
+			decisionlog.Query{User: "alice@acmecorp.com", From: decisiontest.Base.Add(2 * time.Minute), To: decisiontest.Base.Add(43 * time.Minute)},
+			[]string{"3"},
+		},
+		"oldest first":          {decisionlog.Query{OldestFirst: true, Limit: 3}, []string{"1", "2", "3"}},
+		"oldest first on index": {decisionlog.Query{User: "alice@acmecorp.com", OldestFirst: true}, []string{"1", "3", "4"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			q := tc.query
+			result, err := s.Search(context.Background(), &q)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, decisiontest.IDs(result))
+		})
+	}
+}
+
+func TestSearchPages(t *testing.T) {
+	s := newStore(t, &Config{MaxAge: -1})
+
+	// decisions 2 and 3 share a timestamp, pages are ordered by id after the timestamp.
+	require.NoError(t, s.LogBatch([]*api.Decision{
+		decisiontest.Decision("1", 1, "alice@acmecorp.com", "todo.GET.todos", true),
+		decisiontest.Decision("2", 2, "alice@acmecorp.com", "todo.GET.todos", true),
+		decisiontest.Decision("3", 2, "alice@acmecorp.com", "todo.GET.todos", true),
+		decisiontest.Decision("4", 3, "alice@acmecorp.com", "todo.GET.todos", true),
+	}))
+
+	pages := func(q decisionlog.Query) [][]string {
+		var result [][]string
+		for {
+			page, err := s.Search(context.Background(), &q)
+			require.NoError(t, err)
+			if len(page) == 0 {
+				return result
+			}
+			result = append(result, decisiontest.IDs(page))
+			q.After = decisionlog.PositionOf(page[len(page)-1])
+		}
+	}
+
+	assert.Equal(t, [][]string{{"4", "3"}, {"2", "1"}}, pages(decisionlog.Query{Limit: 2}))
+	assert.Equal(t, [][]string{{"1", "2"}, {"3", "4"}}, pages(decisionlog.Query{Limit: 2, OldestFirst: true}))
+	assert.Equal(t, [][]string{{"1", "2", "3"}, {"4"}}, pages(decisionlog.Query{Limit: 3, OldestFirst: true, User: "alice@acmecorp.com"}))
+
+	// following a search from its last position returns the decisions logged since.
+	q := decisionlog.Query{OldestFirst: true, After: decisionlog.PositionOf(decisiontest.Decision("4", 3, "", "", true))}
+	require.NoError(t, s.Log(decisiontest.Decision("5", 3, "bob@acmecorp.com", "todo.GET.todos", true)))
+	result, err := s.Search(context.Background(), &q)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5"}, decisiontest.IDs(result))
+}
+
+func TestRetentionByAge(t *testing.T) {
+	s := newStore(t, &Config{MaxAge: time.Hour})
+
+	require.NoError(t, s.LogBatch([]*api.Decision{
+		decisiontest.Decision("1", 0, "alice@acmecorp.com", "todo.GET.todos", true),
+		decisiontest.Decision("2", 30, "alice@acmecorp.com", "todo.GET.todos", true),
+		decisiontest.Decision("3", 90, "alice@acmecorp.com", "todo.GET.todos", true),
+	}))
+
+	require.NoError(t, s.enforceRetention(decisiontest.Base.Add(100*time.Minute)))
+
+	result, err := s.Search(context.Background(), &decisionlog.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3"}, decisiontest.IDs(result))
+
+	// index entries are removed with the decisions.
+	result, err = s.Search(context.Background(), &decisionlog.Query{User: "alice@acmecorp.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3"}, decisiontest.IDs(result))
+}
+
+func TestRetentionNoMaxAge(t *testing.T) {
+	s := newStore(t, &Config{MaxAge: -1})
+
+	require.NoError(t, s.LogBatch([]*api.Decision{
+		decisiontest.Decision("1", 0, "alice@acmecorp.com", "todo.GET.todos", true),
+	}))
+
+	require.NoError(t, s.enforceRetention(decisiontest.Base.Add(10*365*24*time.Hour)))
+
+	result, err := s.Search(context.Background(), &decisionlog.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, decisiontest.IDs(result))
+}
+
+func TestRetentionBySize(t *testing.T) {
+	s := newStore(t, &Config{MaxAge: -1, MaxSizeMB: 1})
+
+	batch := make([]*api.Decision, 0, 5000)
+	for i := 0; i < 5000; i++ {
+		d := decisiontest.Decision(fmt.Sprintf("%05d", i), i, "alice@acmecorp.com", "todo.GET.todos", true)
+		d.Annotations = map[string]string{"padding": fmt.Sprintf("%0500d", i)}
+		batch = append(batch, d)
+	}
+	require.NoError(t, s.LogBatch(batch))
+
+	size, err := s.size()
+	require.NoError(t, err)
+	require.Greater(t, size, int64(1<<20))
+
+	require.NoError(t, s.enforceRetention(decisiontest.Base))
+
+	size, err = s.size()
+	require.NoError(t, err)
+	assert.LessOrEqual(t, size, int64(1<<20))
+
+	// the most recent decisions are kept.
+	result, err := s.Search(context.Background(), &decisionlog.Query{Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"04999"}, decisiontest.IDs(result))
+
+	result, err = s.Search(context.Background(), &decisionlog.Query{To: decisiontest.Base.Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Empty(t, result)
+}
@@ -0,0 +1,223 @@
+package decisionlog
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Schemas in which decision loggers write their records.
+const (
+	// SchemaTopaz writes the api.Decision records.
+	SchemaTopaz = "topaz"
+	// SchemaOPA writes the decision log events of OPA.
+	SchemaOPA = "opa"
+)
+
+// ValidateSchema returns an error when schema is not a known decision log schema.
+func ValidateSchema(schema string) error {
+	switch schema {
+	case SchemaTopaz, SchemaOPA:
+		return nil
+	default:
+		return errors.Errorf("unknown schema [%s], must be %s or %s", schema, SchemaTopaz, SchemaOPA)
+	}
+}
+
+// Metrics of OPA decision log events, following the OPA metric names.
+const (
+	OPAMetricEval    = "timer_rego_query_eval_ns"
+	OPAMetricDSCalls = "counter_ds_calls"
+)
+
+// defaultBundleName names the bundle of a single bundle revision when the decision has no policy instance.
+const defaultBundleName = "policy"
+
+// OPAEvent is a decision record in the schema of the OPA decision logs.
+type OPAEvent struct {
+	Labels      map[string]string      `json:"labels,omitempty"`
+	DecisionID  string                 `json:"decision_id"`
+	Bundles     map[string]OPABundle   `json:"bundles,omitempty"`
+	Path        string                 `json:"path,omitempty"`
+	Query       string                 `json:"query,omitempty"`
+	Input       map[string]interface{} `json:"input,omitempty"`
+	Result      interface{}            `json:"result,omitempty"`
+	RequestedBy string                 `json:"requested_by,omitempty"`
+	Timestamp   time.Time              `json:"timestamp"`
+	Metrics     map[string]interface{} `json:"metrics,omitempty"`
+	// NDBuiltinCache holds the results of the ds builtin calls, by builtin and JSON encoded arguments.
+	NDBuiltinCache map[string]map[string]interface{} `json:"nd_builtin_cache,omitempty"`
+}
+
+type OPABundle struct {
+	Revision string `json:"revision"`
+}
+
+// NewOPAEvent translates a decision record into an OPA decision log event.
+//
+// The input is rebuilt from the identity, policy and resource contexts of the decision,
+// and the id and email of its user. The result holds the outcomes of the decision, or
+// the result of query and compile calls. Annotations that have no counterpart in the
+// OPA schema are kept as labels.
+func NewOPAEvent(d *api.Decision) *OPAEvent {
+	e := &OPAEvent{
+		DecisionID: d.Id,
+		Path:       strings.ReplaceAll(d.Path, ".", "/"),
+		Input:      opaInput(d),
+		Labels:     map[string]string{},
+		Metrics:    map[string]interface{}{},
+	}
+
+	if d.Timestamp != nil {
+		e.Timestamp = d.Timestamp.AsTime()
+	}
+
+	if len(d.Outcomes) > 0 {
+		e.Result = d.Outcomes
+	}
+
+	for k, v := range d.Annotations {
+		switch k {
+		case AnnotationQuery:
+			e.Query = v
+		case AnnotationResult:
+			var result interface{}
+			if err := json.Unmarshal([]byte(v), &result); err == nil {
+				e.Result = result
+			}
+		case AnnotationBundleRevision:
+			e.Bundles = opaBundles(d, v)
+		case AnnotationEvalDuration:
+			setMetric(e.Metrics, OPAMetricEval, v)
+		case AnnotationEvalDSCalls:
+			setMetric(e.Metrics, OPAMetricDSCalls, v)
+		case AnnotationEvalDSTrace:
+			e.NDBuiltinCache = opaBuiltinCache(v)
+		case AnnotationVersion:
+			e.Labels["version"] = v
+		default:
+			e.Labels[k] = v
+		}
+	}
+
+	if d.TenantId != nil {
+		e.Labels["tenant_id"] = d.GetTenantId()
+	}
+
+	return e
+}
+
+func opaInput(d *api.Decision) map[string]interface{} {
+	input := map[string]interface{}{}
+
+	if d.User != nil {
+		if d.User.Context != nil {
+			input["identity"] = protoToMap(d.User.Context)
+		}
+
+		user := map[string]interface{}{}
+		if d.User.Id != "" {
+			user["id"] = d.User.Id
+		}
+		if d.User.Email != "" {
+			user["email"] = d.User.Email
+		}
+		if len(user) > 0 {
+			input["user"] = user
+		}
+	}
+
+	if d.Policy != nil && d.Policy.Context != nil {
+		input["policy"] = protoToMap(d.Policy.Context)
+	}
+
+	if d.Resource != nil {
+		input["resource"] = d.Resource.AsMap()
+	}
+
+	return input
+}
+
+// opaBundles parses the bundle.revision annotation. A single revision, written when
+// only one bundle is active, is named after the policy instance of the decision.
+func opaBundles(d *api.Decision, revision string) map[string]OPABundle {
+	bundles := map[string]OPABundle{}
+
+	pairs := strings.Split(revision, ",")
+	if len(pairs) == 1 && !strings.Contains(revision, "=") {
+		name := d.GetPolicy().GetPolicyInstance().GetName()
+		if name == "" {
+			name = defaultBundleName
+		}
+		bundles[name] = OPABundle{Revision: revision}
+		return bundles
+	}
+
+	for _, pair := range pairs {
+		if name, rev, ok := strings.Cut(pair, "="); ok {
+			bundles[name] = OPABundle{Revision: rev}
+		}
+	}
+
+	return bundles
+}
+
+// opaBuiltinCache converts the recorded ds builtin calls into the non-deterministic builtin
+// cache of OPA, which is keyed by the JSON encoded array of the arguments. Failed calls are left out.
+func opaBuiltinCache(trace string) map[string]map[string]interface{} {
+	var calls []struct {
+		Builtin string      `json:"builtin"`
+		Args    interface{} `json:"args"`
+		Result  interface{} `json:"result"`
+		Error   string      `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(trace), &calls); err != nil {
+		return nil
+	}
+
+	cache := map[string]map[string]interface{}{}
+	for _, c := range calls {
+		if c.Error != "" {
+			continue
+		}
+
+		key, err := json.Marshal([]interface{}{c.Args})
+		if err != nil {
+			continue
+		}
+
+		if cache[c.Builtin] == nil {
+			cache[c.Builtin] = map[string]interface{}{}
+		}
+		cache[c.Builtin][string(key)] = c.Result
+	}
+
+	return cache
+}
+
+func setMetric(metrics map[string]interface{}, name, value string) {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		metrics[name] = n
+	}
+}
+
+// protoToMap converts msg in the same way the authorizer converts it into the policy input.
+func protoToMap(msg proto.Message) map[string]interface{} {
+	b, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+
+	return m
+}
@@ -0,0 +1,91 @@
+package decisionlog_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestNewOPAEvent(t *testing.T) {
+	ts := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	resource, err := structpb.NewStruct(map[string]interface{}{"owner": "alice"})
+	require.NoError(t, err)
+
+	d := &api.Decision{
+		Id:        "d1",
+		Timestamp: timestamppb.New(ts),
+		Path:      "todo.GET.todos",
+		User: &api.DecisionUser{
+			Context: &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_SUB, Identity: "alice"},
+			Id:      "alice-id",
+			Email:   "alice@acmecorp.com",
+		},
+		Policy: &api.DecisionPolicy{
+			Context:        &api.PolicyContext{Path: "todo.GET.todos", Decisions: []string{"allowed"}},
+			PolicyInstance: &api.PolicyInstance{Name: "todo"},
+		},
+		Resource: resource,
+		Outcomes: map[string]bool{"allowed": true},
+		TenantId: proto.String("acme"),
+		Annotations: map[string]string{
+			decisionlog.AnnotationAPI:            "is",
+			decisionlog.AnnotationBundleRevision: "r42",
+			decisionlog.AnnotationEvalDuration:   "1500",
+			decisionlog.AnnotationEvalDSCalls:    "2",
+			decisionlog.AnnotationVersion:        "0.30.0",
+		},
+	}
+
+	b, err := json.Marshal(decisionlog.NewOPAEvent(d))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"decision_id": "d1",
+		"path": "todo/GET/todos",
+		"timestamp": "2023-05-01T12:00:00Z",
+		"labels": {"api": "is", "version": "0.30.0", "tenant_id": "acme"},
+		"bundles": {"todo": {"revision": "r42"}},
+		"input": {
+			"identity": {"type": "IDENTITY_TYPE_SUB", "identity": "alice"},
+			"user": {"id": "alice-id", "email": "alice@acmecorp.com"},
+			"policy": {"path": "todo.GET.todos", "decisions": ["allowed"]},
+			"resource": {"owner": "alice"}
+		},
+		"result": {"allowed": true},
+		"metrics": {"timer_rego_query_eval_ns": 1500, "counter_ds_calls": 2}
+	}`, string(b))
+}
+
+func TestNewOPAEventQuery(t *testing.T) {
+	d := &api.Decision{
+		Id: "d2",
+		Annotations: map[string]string{
+			decisionlog.AnnotationAPI:            "query",
+			decisionlog.AnnotationQuery:          "x = data.todo.GET.todos.allowed",
+			decisionlog.AnnotationResult:         `[{"bindings":{"x":false}}]`,
+			decisionlog.AnnotationBundleRevision: "peoplefinder=a1,todo=r42",
+			decisionlog.AnnotationEvalDSTrace: `[` +
+				`{"builtin":"ds.check_relation","args":{"relation":{"name":"member"}},"result":true,"duration_ns":1200},` +
+				`{"builtin":"ds.object","args":{"key":"x"},"error":"not found","duration_ns":800}]`,
+		},
+	}
+
+	e := decisionlog.NewOPAEvent(d)
+
+	assert.Equal(t, "x = data.todo.GET.todos.allowed", e.Query)
+	assert.Equal(t, []interface{}{map[string]interface{}{"bindings": map[string]interface{}{"x": false}}}, e.Result)
+	assert.Equal(t, map[string]decisionlog.OPABundle{"peoplefinder": {Revision: "a1"}, "todo": {Revision: "r42"}}, e.Bundles)
+	assert.Equal(t, map[string]map[string]interface{}{
+		"ds.check_relation": {`[{"relation":{"name":"member"}}]`: true},
+	}, e.NDBuiltinCache)
+	assert.Equal(t, map[string]string{"api": "query"}, e.Labels)
+	assert.Empty(t, e.Path)
+}
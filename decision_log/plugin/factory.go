@@ -26,7 +26,7 @@ func (f Factory) New(m *plugins.Manager, config interface{}) plugins.Plugin {
 	return newDecisionLogger(cfg, m, f.logger)
 }
 
-func (Factory) Validate(m *plugins.Manager, config []byte) (interface{}, error) {
+func (f Factory) Validate(m *plugins.Manager, config []byte) (interface{}, error) {
 	parsedConfig := Config{}
 	v := viper.New()
 	v.SetConfigType("json")
@@ -41,5 +41,14 @@ func (Factory) Validate(m *plugins.Manager, config []byte) (interface{}, error)
 		return nil, errors.Wrap(err, "error parsing decision logs config")
 	}
 
-	return &parsedConfig, util.Unmarshal(config, &parsedConfig)
+	if err := util.Unmarshal(config, &parsedConfig); err != nil {
+		return nil, err
+	}
+
+	// records are chained one at a time, the decision logger must not hold the chain while writing.
+	if parsedConfig.HashChain.Enabled && f.logger != nil && !decisionlog.Queuing(f.logger) {
+		return nil, errors.New("hash_chain requires the async option of the decision logger")
+	}
+
+	return &parsedConfig, parsedConfig.validate()
 }
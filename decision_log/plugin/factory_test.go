@@ -0,0 +1,34 @@
+package plugin
+
+import (
+	"testing"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Log(*api.Decision) error { return nil }
+func (nopLogger) Shutdown()               {}
+
+func TestFactoryHashChainRequiresQueuing(t *testing.T) {
+	config := []byte(`{"enabled": true, "hash_chain": {"enabled": true}}`)
+
+	_, err := NewFactory(nopLogger{}).Validate(nil, config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires the async option")
+
+	logger := zerolog.Nop()
+	async, err := decisionlog.NewAsync(nopLogger{}, &decisionlog.AsyncConfig{Enabled: true}, "test", &logger)
+	require.NoError(t, err)
+	defer async.Shutdown()
+
+	// past the logger check, the chain config itself is validated.
+	_, err = NewFactory(async).Validate(nil, config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signing_key_path not set")
+}
@@ -2,15 +2,39 @@ package plugin
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
 	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/chain"
+	"github.com/aserto-dev/topaz/decision_log/redact"
+	"github.com/aserto-dev/topaz/pkg/version"
 
 	"github.com/open-policy-agent/opa/plugins"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 const PluginName = "aserto_decision_log"
 
+// Authorizer calls that can produce decision records.
+const (
+	APIIs           = "is"
+	APIDecisionTree = "decision_tree"
+	APIQuery        = "query"
+	APICompile      = "compile"
+)
+
+var logErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "topaz",
+	Subsystem: "decision_log",
+	Name:      "errors_total",
+	Help:      "Number of decisions that could not be logged.",
+}, []string{"api"})
+
 type PolicyInfo struct {
 	PolicyID        string `json:"policy_id"`
 	PolicyName      string `json:"policy_name"`
@@ -23,44 +47,241 @@ type PolicyInfo struct {
 type Config struct {
 	Enabled    bool       `json:"enabled"`
 	PolicyInfo PolicyInfo `json:"policy_info"`
+	// APIs lists the authorizer calls that are logged, only is calls are logged when empty.
+	APIs []string `json:"apis"`
+	// Sampling rules select the decisions that are logged, all decisions are logged when empty.
+	Sampling []SamplingRule `json:"sampling"`
+	// Redaction rules are applied to every decision before it is handed to the decision logger.
+	Redaction redact.Config `json:"redaction"`
+	// HashChain links every decision record to the previous one and periodically writes signed checkpoints.
+	HashChain chain.Config `json:"hash_chain"`
+	// RecordDSCalls records every ds builtin call made by a decision on its record.
+	RecordDSCalls bool `json:"record_ds_calls"`
+
+	sampler  *sampler
+	redactor *redact.Redactor
+}
+
+func (cfg *Config) validate() error {
+	for _, a := range cfg.APIs {
+		switch a {
+		case APIIs, APIDecisionTree, APIQuery, APICompile:
+		default:
+			return errors.Errorf("unknown api [%s] in apis, must be one of %s, %s, %s or %s", a, APIIs, APIDecisionTree, APIQuery, APICompile)
+		}
+	}
+
+	sampler, err := newSampler(cfg.Sampling)
+	if err != nil {
+		return errors.Wrap(err, "invalid sampling config")
+	}
+	cfg.sampler = sampler
+
+	redactor, err := redact.New(&cfg.Redaction)
+	if err != nil {
+		return errors.Wrap(err, "invalid redaction config")
+	}
+	cfg.redactor = redactor
+
+	if err := cfg.HashChain.Validate(); err != nil {
+		return errors.Wrap(err, "invalid hash_chain config")
+	}
+
+	return nil
 }
+
 type DecisionLogsPlugin struct {
 	manager *plugins.Manager
 	cfg     *Config
 	logger  decisionlog.DecisionLogger
+	// revision of the active bundles, see updateRevision.
+	revision atomic.Value
+
+	mu    sync.Mutex
+	chain *chain.Chain
+	stop  chan struct{}
+	done  chan struct{}
 }
 
 func newDecisionLogger(cfg *Config, manager *plugins.Manager, logger decisionlog.DecisionLogger) *DecisionLogsPlugin {
-	return &DecisionLogsPlugin{
+	plugin := &DecisionLogsPlugin{
 		manager: manager,
 		cfg:     cfg,
 		logger:  logger,
 	}
+	manager.RegisterCompilerTrigger(plugin.updateRevision)
+	return plugin
 }
 func (plugin *DecisionLogsPlugin) Start(ctx context.Context) error {
+	plugin.loadRevision(ctx)
+	if err := plugin.startChain(); err != nil {
+		return err
+	}
 	plugin.manager.UpdatePluginStatus(PluginName, &plugins.Status{State: plugins.StateOK})
 	return nil
 }
 func (plugin *DecisionLogsPlugin) Stop(ctx context.Context) {
+	plugin.stopChain()
 	plugin.manager.UpdatePluginStatus(PluginName, &plugins.Status{State: plugins.StateNotReady})
 }
 func (plugin *DecisionLogsPlugin) Reconfigure(ctx context.Context, config interface{}) {
+	plugin.stopChain()
 	plugin.cfg = config.(*Config)
+	if err := plugin.startChain(); err != nil {
+		plugin.manager.Logger().Error("%v", err)
+	}
 }
 
-func (plugin *DecisionLogsPlugin) Log(ctx context.Context, d *api.Decision) error {
+// Enabled reports whether decisions made by the given authorizer call are logged.
+func (plugin *DecisionLogsPlugin) Enabled(apiName string) bool {
 	if !plugin.cfg.Enabled || plugin.logger == nil {
-		return nil
+		return false
+	}
+
+	if len(plugin.cfg.APIs) == 0 {
+		return apiName == APIIs
 	}
 
+	for _, a := range plugin.cfg.APIs {
+		if a == apiName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RecordDSCalls reports whether the ds builtin calls of the logged decisions are recorded.
+func (plugin *DecisionLogsPlugin) RecordDSCalls() bool {
+	return plugin.cfg.RecordDSCalls
+}
+
+// decisionAPI returns the authorizer call that produced the decision, records without it are is calls.
+func decisionAPI(d *api.Decision) string {
+	if a, ok := d.Annotations[decisionlog.AnnotationAPI]; ok {
+		return a
+	}
+	return APIIs
+}
+
+// Log hands the decision to the decision logger, when its call is logged and it is sampled.
+// Decisions that cannot be logged are reported and counted, they never fail the call that
+// made them.
+func (plugin *DecisionLogsPlugin) Log(ctx context.Context, d *api.Decision) {
+	apiName := decisionAPI(d)
+	if !plugin.Enabled(apiName) {
+		return
+	}
+
+	if plugin.cfg.sampler != nil && !plugin.cfg.sampler.sample(d) {
+		return
+	}
+
+	if err := plugin.write(d); err != nil {
+		logErrors.WithLabelValues(apiName).Inc()
+		plugin.manager.Logger().Error("failed to log decision %s: %v", d.Id, err)
+	}
+}
+
+// write completes the decision record and writes it to the decision logger.
+func (plugin *DecisionLogsPlugin) write(d *api.Decision) error {
 	d.Policy.RegistryService = plugin.cfg.PolicyInfo.RegistryService
 	d.Policy.RegistryImage = plugin.cfg.PolicyInfo.RegistryImage
 	d.Policy.RegistryTag = plugin.cfg.PolicyInfo.RegistryTag
 	d.Policy.RegistryDigest = plugin.cfg.PolicyInfo.Digest
 
+	if d.Annotations == nil {
+		d.Annotations = map[string]string{}
+	}
+	if revision := plugin.currentRevision(); revision != "" {
+		d.Annotations[decisionlog.AnnotationBundleRevision] = revision
+	}
+	d.Annotations[decisionlog.AnnotationVersion] = version.GetInfo().Version
+
+	if plugin.cfg.redactor != nil {
+		redacted, err := plugin.cfg.redactor.Redact(d)
+		if err != nil {
+			// never hand an unredacted decision to the logger.
+			return errors.Wrap(err, "failed to redact decision")
+		}
+		d = redacted
+	}
+
+	if c := plugin.currentChain(); c != nil {
+		return c.Append(d, plugin.logger.Log)
+	}
+
 	return plugin.logger.Log(d)
 }
 
+// Reader returns the reader of the decision logger, when decisions can be read back from it.
+func (plugin *DecisionLogsPlugin) Reader() (decisionlog.DecisionReader, bool) {
+	return decisionlog.Reader(plugin.logger)
+}
+
+// startChain starts a new hash chain, when enabled, along with the goroutine writing
+// its checkpoints at the configured interval.
+func (plugin *DecisionLogsPlugin) startChain() error {
+	if !plugin.cfg.HashChain.Enabled || plugin.logger == nil {
+		return nil
+	}
+
+	c, err := chain.New(&plugin.cfg.HashChain)
+	if err != nil {
+		return errors.Wrap(err, "failed to start hash chain")
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+
+	plugin.mu.Lock()
+	plugin.chain, plugin.stop, plugin.done = c, stop, done
+	plugin.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(plugin.cfg.HashChain.Interval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := c.Checkpoint(plugin.logger.Log); err != nil {
+					plugin.manager.Logger().Error("%v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// stopChain ends the current hash chain with a final checkpoint.
+func (plugin *DecisionLogsPlugin) stopChain() {
+	plugin.mu.Lock()
+	c, stop, done := plugin.chain, plugin.stop, plugin.done
+	plugin.chain, plugin.stop, plugin.done = nil, nil, nil
+	plugin.mu.Unlock()
+
+	if c == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+
+	if err := c.Checkpoint(plugin.logger.Log); err != nil {
+		plugin.manager.Logger().Error("%v", err)
+	}
+}
+
+func (plugin *DecisionLogsPlugin) currentChain() *chain.Chain {
+	plugin.mu.Lock()
+	defer plugin.mu.Unlock()
+	return plugin.chain
+}
+
 func Lookup(m *plugins.Manager) *DecisionLogsPlugin {
 	p := m.Plugin(PluginName)
 	if p == nil {
@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/storage"
+)
+
+// updateRevision reads the revisions of the active bundles, it is called every time
+// the policy compiler changes, which includes every bundle activation.
+func (plugin *DecisionLogsPlugin) updateRevision(txn storage.Transaction) {
+	revision, err := ReadRevision(context.Background(), plugin.manager.Store, txn)
+	if err != nil {
+		plugin.manager.Logger().Error("failed to read bundle revisions: %v", err)
+		return
+	}
+
+	plugin.revision.Store(revision)
+}
+
+// loadRevision reads the revisions of the bundles activated before the plugin started.
+func (plugin *DecisionLogsPlugin) loadRevision(ctx context.Context) {
+	err := storage.Txn(ctx, plugin.manager.Store, storage.TransactionParams{}, func(txn storage.Transaction) error {
+		plugin.updateRevision(txn)
+		return nil
+	})
+	if err != nil {
+		plugin.manager.Logger().Error("failed to read bundle revisions: %v", err)
+	}
+}
+
+func (plugin *DecisionLogsPlugin) currentRevision() string {
+	revision, _ := plugin.revision.Load().(string)
+	return revision
+}
+
+// ReadRevision returns the revision of the active bundle, or the name=revision pairs of
+// all active bundles, sorted by name, when there are several.
+func ReadRevision(ctx context.Context, store storage.Store, txn storage.Transaction) (string, error) {
+	names, err := bundle.ReadBundleNamesFromStore(ctx, store, txn)
+	if storage.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(names)
+
+	revisions := make([]string, 0, len(names))
+	for _, name := range names {
+		revision, err := bundle.ReadBundleRevisionFromStore(ctx, store, txn, name)
+		if storage.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if len(names) == 1 {
+			return revision, nil
+		}
+		revisions = append(revisions, name+"="+revision)
+	}
+
+	return strings.Join(revisions, ","), nil
+}
@@ -0,0 +1,49 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/storage/inmem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRevision(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		manifests map[string]string
+		revision  string
+	}{
+		"no bundles":  {nil, ""},
+		"one bundle":  {map[string]string{"peoplefinder": "a1b2c3"}, "a1b2c3"},
+		"two bundles": {map[string]string{"todo": "v2", "peoplefinder": "a1b2c3"}, "peoplefinder=a1b2c3,todo=v2"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := inmem.New()
+
+			err := storage.Txn(ctx, store, storage.WriteParams, func(txn storage.Transaction) error {
+				for name, revision := range tc.manifests {
+					if err := bundle.WriteManifestToStore(ctx, store, txn, name, bundle.Manifest{Revision: revision}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+
+			var revision string
+			err = storage.Txn(ctx, store, storage.TransactionParams{}, func(txn storage.Transaction) error {
+				revision, err = ReadRevision(ctx, store, txn)
+				return err
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.revision, revision)
+		})
+	}
+}
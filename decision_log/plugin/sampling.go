@@ -0,0 +1,139 @@
+package plugin
+
+import (
+	"math/rand"
+	"strings"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
+)
+
+const identityTypePrefix = "IDENTITY_TYPE_"
+
+// SamplingRule selects decisions and the fraction of them that is logged.
+// Empty criteria match every decision.
+type SamplingRule struct {
+	// Path is a glob matched against the policy path, "*" matches a single path
+	// segment and "**" any number of segments, e.g. peoplefinder.GET.**.
+	Path string `json:"path"`
+	// Outcome is allow, when all outcomes of the decision are true, deny, or none for
+	// records without outcomes such as those of query and compile calls.
+	Outcome string `json:"outcome"`
+	// API is the authorizer call that produced the decision: is, decision_tree, query or compile.
+	API string `json:"api"`
+	// TenantID matches the tenant of the decision.
+	TenantID string `json:"tenant_id"`
+	// IdentityType is one of none, sub, jwt or manual.
+	IdentityType string `json:"identity_type"`
+	// SampleRate is the fraction of matching decisions that is logged, between 0 and 1.
+	// All matching decisions are logged when not set.
+	SampleRate *float64 `json:"sample_rate"`
+}
+
+type samplingRule struct {
+	SamplingRule
+	path glob.Glob
+}
+
+// sampler decides whether a decision is logged, using the first rule that
+// matches it. Decisions matching no rule are logged.
+type sampler struct {
+	rules []samplingRule
+	rand  func() float64
+}
+
+func newSampler(rules []SamplingRule) (*sampler, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	s := &sampler{rand: rand.Float64} //nolint: gosec
+
+	for i, r := range rules {
+		rule := samplingRule{SamplingRule: r}
+
+		if r.Path != "" {
+			g, err := glob.Compile(r.Path, '.')
+			if err != nil {
+				return nil, errors.Wrapf(err, "sampling rule %d: invalid path [%s]", i, r.Path)
+			}
+			rule.path = g
+		}
+
+		switch r.Outcome {
+		case "", decisionlog.OutcomeAllow, decisionlog.OutcomeDeny, decisionlog.OutcomeNone:
+		default:
+			return nil, errors.Errorf("sampling rule %d: unknown outcome [%s], must be %s, %s or %s",
+				i, r.Outcome, decisionlog.OutcomeAllow, decisionlog.OutcomeDeny, decisionlog.OutcomeNone)
+		}
+
+		switch r.API {
+		case "", APIIs, APIDecisionTree, APIQuery, APICompile:
+		default:
+			return nil, errors.Errorf("sampling rule %d: unknown api [%s], must be one of %s, %s, %s or %s",
+				i, r.API, APIIs, APIDecisionTree, APIQuery, APICompile)
+		}
+
+		if r.IdentityType != "" {
+			if _, ok := api.IdentityType_value[identityTypePrefix+strings.ToUpper(r.IdentityType)]; !ok {
+				return nil, errors.Errorf("sampling rule %d: unknown identity type [%s]", i, r.IdentityType)
+			}
+		}
+
+		if r.SampleRate != nil && (*r.SampleRate < 0 || *r.SampleRate > 1) {
+			return nil, errors.Errorf("sampling rule %d: sample_rate must be between 0 and 1", i)
+		}
+
+		s.rules = append(s.rules, rule)
+	}
+
+	return s, nil
+}
+
+func (s *sampler) sample(d *api.Decision) bool {
+	for i := range s.rules {
+		r := &s.rules[i]
+		if !r.matches(d) {
+			continue
+		}
+
+		if r.SampleRate == nil {
+			return true
+		}
+		return s.rand() < *r.SampleRate
+	}
+
+	return true
+}
+
+func (r *samplingRule) matches(d *api.Decision) bool {
+	if r.path != nil && !r.path.Match(d.Path) {
+		return false
+	}
+
+	if r.Outcome != "" && r.Outcome != decisionlog.Outcome(d) {
+		return false
+	}
+
+	if r.API != "" && r.API != decisionAPI(d) {
+		return false
+	}
+
+	if r.TenantID != "" && r.TenantID != d.GetTenantId() {
+		return false
+	}
+
+	if r.IdentityType != "" {
+		identityType := api.IdentityType_IDENTITY_TYPE_UNKNOWN
+		if d.User != nil && d.User.Context != nil {
+			identityType = d.User.Context.Type
+		}
+		if !strings.EqualFold(identityTypePrefix+r.IdentityType, identityType.String()) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"testing"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func rate(r float64) *float64 {
+	return &r
+}
+
+func TestSampling(t *testing.T) {
+	s, err := newSampler([]SamplingRule{
+		{Outcome: decisionlog.OutcomeDeny},
+		{Path: "peoplefinder.GET.**", IdentityType: "jwt", SampleRate: rate(0.1)},
+		{TenantID: "noisy", SampleRate: rate(0)},
+		{API: APIQuery, SampleRate: rate(0)},
+	})
+	require.NoError(t, err)
+	s.rand = func() float64 { return 0.5 }
+
+	jwt := &api.DecisionUser{Context: &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_JWT}}
+	sub := &api.DecisionUser{Context: &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_SUB}}
+
+	tests := map[string]struct {
+		decision *api.Decision
+		logged   bool
+	}{
+		"deny always logged": {
+			&api.Decision{Path: "peoplefinder.GET.users", User: jwt, Outcomes: map[string]bool{"allowed": true, "visible": false}},
+			true,
+		},
+		"sampled allow": {
+			&api.Decision{Path: "peoplefinder.GET.api.users.__id", User: jwt, Outcomes: map[string]bool{"allowed": true}},
+			false,
+		},
+		"identity type mismatch": {
+			&api.Decision{Path: "peoplefinder.GET.users", User: sub, Outcomes: map[string]bool{"allowed": true}},
+			true,
+		},
+		"path mismatch": {
+			&api.Decision{Path: "peoplefinder.POST.users", User: jwt, Outcomes: map[string]bool{"allowed": true}},
+			true,
+		},
+		"tenant dropped": {
+			&api.Decision{Path: "todo.GET.todos", TenantId: proto.String("noisy"), Outcomes: map[string]bool{"allowed": true}},
+			false,
+		},
+		"query dropped": {
+			&api.Decision{Path: "todo", Annotations: map[string]string{decisionlog.AnnotationAPI: APIQuery}},
+			false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.logged, s.sample(tc.decision))
+		})
+	}
+
+	s.rand = func() float64 { return 0.05 }
+	assert.True(t, s.sample(tests["sampled allow"].decision))
+}
+
+func TestSamplingOutcomeNone(t *testing.T) {
+	s, err := newSampler([]SamplingRule{{Outcome: decisionlog.OutcomeAllow, SampleRate: rate(0)}})
+	require.NoError(t, err)
+
+	// records without outcomes are not allows.
+	assert.False(t, s.sample(&api.Decision{Outcomes: map[string]bool{"allowed": true}}))
+	assert.True(t, s.sample(&api.Decision{Annotations: map[string]string{decisionlog.AnnotationAPI: APICompile}}))
+}
+
+func TestSamplingInvalidRules(t *testing.T) {
+	for name, rule := range map[string]SamplingRule{
+		"outcome":       {Outcome: "maybe"},
+		"api":           {API: "explain"},
+		"identity type": {IdentityType: "token"},
+		"sample rate":   {SampleRate: rate(1.5)},
+		"path":          {Path: "[a"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := newSampler([]SamplingRule{rule})
+			assert.Error(t, err)
+		})
+	}
+}
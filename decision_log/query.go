@@ -0,0 +1,236 @@
+package decisionlog
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
+)
+
+const (
+	OutcomeAllow = "allow"
+	OutcomeDeny  = "deny"
+	// OutcomeNone is the outcome of records without decisions, such as those of query and compile calls.
+	OutcomeNone = "none"
+)
+
+const (
+	DefaultQueryLimit = 100
+	MaxQueryLimit     = 10000
+)
+
+// Outcome returns allow when all outcomes of the decision are true, deny when one of them is
+// false and none when the decision has no outcomes.
+func Outcome(d *api.Decision) string {
+	if len(d.Outcomes) == 0 {
+		return OutcomeNone
+	}
+	for _, v := range d.Outcomes {
+		if !v {
+			return OutcomeDeny
+		}
+	}
+	return OutcomeAllow
+}
+
+// Query selects decisions read back from a decision logger. Empty criteria match every decision.
+type Query struct {
+	// From and To bound the decision timestamp, To is exclusive.
+	From time.Time
+	To   time.Time
+	// User matches the user id or, ignoring case, the user email.
+	User string
+	// Path is a glob matched against the policy path, "*" matches a single path
+	// segment and "**" any number of segments.
+	Path string
+	// Outcome is allow, deny or none.
+	Outcome  string
+	TenantID string
+	// Limit is the maximum number of decisions returned, the first ones in the order of the query are kept.
+	Limit int
+	// OldestFirst returns the oldest decisions first instead of the most recent ones.
+	OldestFirst bool
+	// After continues a previous search of the same query after the position of its last decision.
+	After *Position
+
+	path glob.Glob
+}
+
+// Position is the place of a decision in the order of searches, by timestamp and then by id.
+type Position struct {
+	Time time.Time
+	ID   string
+}
+
+// PositionOf returns the position of a decision.
+func PositionOf(d *api.Decision) *Position {
+	return &Position{Time: d.GetTimestamp().AsTime(), ID: d.GetId()}
+}
+
+// before reports whether p comes before o.
+func (p *Position) before(o *Position) bool {
+	if !p.Time.Equal(o.Time) {
+		return p.Time.Before(o.Time)
+	}
+	return p.ID < o.ID
+}
+
+// Token encodes the position as an opaque page token.
+func (p *Position) Token() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(p.Time.UnixNano(), 10) + ":" + p.ID))
+}
+
+// ParsePosition decodes a page token returned by Token.
+func ParsePosition(token string) (*Position, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.New("invalid page token")
+	}
+
+	ts, id, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return nil, errors.New("invalid page token")
+	}
+
+	ns, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid page token")
+	}
+
+	return &Position{Time: time.Unix(0, ns).UTC(), ID: id}, nil
+}
+
+// Range returns the time range of the decisions the query can select, narrowed by its
+// After position. To is exclusive.
+func (q *Query) Range() (from, to time.Time) {
+	from, to = q.From, q.To
+
+	switch {
+	case q.After == nil:
+	case q.OldestFirst && q.After.Time.After(from):
+		from = q.After.Time
+	case !q.OldestFirst && (to.IsZero() || q.After.Time.Before(to)):
+		// decisions sharing the timestamp of the position may still follow it.
+		to = q.After.Time.Add(time.Nanosecond)
+	}
+
+	return from, to
+}
+
+// Validate checks the query and prepares it for Match.
+func (q *Query) Validate() error {
+	if q.Limit == 0 {
+		q.Limit = DefaultQueryLimit
+	}
+	if q.Limit < 0 || q.Limit > MaxQueryLimit {
+		return errors.Errorf("limit must be between 1 and %d", MaxQueryLimit)
+	}
+
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return errors.New("from must be before to")
+	}
+
+	switch q.Outcome {
+	case "", OutcomeAllow, OutcomeDeny, OutcomeNone:
+	default:
+		return errors.Errorf("unknown outcome [%s], must be %s, %s or %s", q.Outcome, OutcomeAllow, OutcomeDeny, OutcomeNone)
+	}
+
+	q.path = nil
+	if q.Path != "" {
+		g, err := glob.Compile(q.Path, '.')
+		if err != nil {
+			return errors.Wrapf(err, "invalid path [%s]", q.Path)
+		}
+		q.path = g
+	}
+
+	return nil
+}
+
+// Match reports whether the decision is selected by the query. The query must have been validated.
+func (q *Query) Match(d *api.Decision) bool {
+	if !q.From.IsZero() || !q.To.IsZero() {
+		ts := d.GetTimestamp().AsTime()
+		if !q.From.IsZero() && ts.Before(q.From) {
+			return false
+		}
+		if !q.To.IsZero() && !ts.Before(q.To) {
+			return false
+		}
+	}
+
+	if q.User != "" && d.GetUser().GetId() != q.User && !strings.EqualFold(d.GetUser().GetEmail(), q.User) {
+		return false
+	}
+
+	if q.path != nil && !q.path.Match(d.Path) {
+		return false
+	}
+
+	if q.Outcome != "" && q.Outcome != Outcome(d) {
+		return false
+	}
+
+	if q.TenantID != "" && q.TenantID != d.GetTenantId() {
+		return false
+	}
+
+	if q.After != nil {
+		p := PositionOf(d)
+		if q.OldestFirst && !q.After.before(p) || !q.OldestFirst && !p.before(q.After) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Page sorts the decisions in the order of the query and keeps at most limit of them.
+func (q *Query) Page(decisions []*api.Decision) []*api.Decision {
+	sort.SliceStable(decisions, func(i, j int) bool {
+		if q.OldestFirst {
+			return PositionOf(decisions[i]).before(PositionOf(decisions[j]))
+		}
+		return PositionOf(decisions[j]).before(PositionOf(decisions[i]))
+	})
+
+	if q.Limit > 0 && len(decisions) > q.Limit {
+		decisions = decisions[:q.Limit]
+	}
+
+	return decisions
+}
+
+// ErrNotReadable is returned when decisions are searched on a decision logger that cannot read them back.
+var ErrNotReadable = errors.New("the decision logger does not support reading decisions")
+
+// DecisionReader is implemented by decision loggers that can read back the decisions they wrote.
+type DecisionReader interface {
+	// Search returns the decisions matching the query, in the order of the query.
+	Search(ctx context.Context, q *Query) ([]*api.Decision, error)
+}
+
+// Reader returns the DecisionReader of a decision logger, looking through wrappers
+// such as the AsyncLogger. It returns false when the decision logger cannot be read back.
+func Reader(l DecisionLogger) (DecisionReader, bool) {
+	for l != nil {
+		if r, ok := l.(DecisionReader); ok {
+			return r, true
+		}
+
+		u, ok := l.(interface{ Unwrap() DecisionLogger })
+		if !ok {
+			return nil, false
+		}
+		l = u.Unwrap()
+	}
+
+	return nil, false
+}
@@ -0,0 +1,30 @@
+package decisionlog_test
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionToken(t *testing.T) {
+	p := &decisionlog.Position{Time: time.Date(2023, 6, 1, 10, 0, 0, 42, time.UTC), ID: "a:b"}
+
+	parsed, err := decisionlog.ParsePosition(p.Token())
+	require.NoError(t, err)
+	assert.Equal(t, p, parsed)
+
+	for _, token := range []string{"", "!", "bm90LWEtcG9zaXRpb24"} {
+		_, err := decisionlog.ParsePosition(token)
+		assert.Error(t, err, token)
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, decisionlog.OutcomeAllow, decisionlog.Outcome(&api.Decision{Outcomes: map[string]bool{"allowed": true, "visible": true}}))
+	assert.Equal(t, decisionlog.OutcomeDeny, decisionlog.Outcome(&api.Decision{Outcomes: map[string]bool{"allowed": true, "visible": false}}))
+	assert.Equal(t, decisionlog.OutcomeNone, decisionlog.Outcome(&api.Decision{}))
+}
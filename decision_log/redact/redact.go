@@ -0,0 +1,295 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type Action string
+
+const (
+	// ActionDrop removes the field from the decision.
+	ActionDrop Action = "drop"
+	// ActionHash replaces the field with a salted HMAC-SHA256 of its value.
+	ActionHash Action = "hash"
+	// ActionTruncate keeps the first Length characters of the field.
+	ActionTruncate Action = "truncate"
+)
+
+const (
+	wildcard   = "*"
+	hashPrefix = "sha256:"
+)
+
+// Rule redacts the decision fields addressed by Path.
+//
+// Paths use the JSON field names of the decision record separated by dots, e.g.
+// user.context.identity or resource.owner.email. A "*" segment matches every key
+// of an object or every element of an array.
+type Rule struct {
+	Path   string `json:"path"`
+	Action Action `json:"action"`
+	// Length is the number of characters kept by the truncate action.
+	Length int `json:"length"`
+	// Salt overrides the global salt for the hash action.
+	Salt string `json:"salt"`
+}
+
+type Config struct {
+	Salt  string `json:"salt"`
+	Rules []Rule `json:"rules"`
+}
+
+type rule struct {
+	Rule
+	segments []string
+}
+
+// Redactor applies redaction rules to decisions.
+type Redactor struct {
+	rules []rule
+	salt  string
+}
+
+// New validates the configured rules and returns a Redactor, or nil when there are no rules.
+func New(cfg *Config) (*Redactor, error) {
+	if cfg == nil || len(cfg.Rules) == 0 {
+		return nil, nil
+	}
+
+	r := &Redactor{salt: cfg.Salt}
+
+	for i, rl := range cfg.Rules {
+		path := strings.TrimPrefix(strings.TrimPrefix(rl.Path, "$"), ".")
+		if path == "" {
+			return nil, errors.Errorf("redaction rule %d: path not set", i)
+		}
+
+		segments := strings.Split(path, ".")
+		for _, s := range segments {
+			if s == "" {
+				return nil, errors.Errorf("redaction rule %d: invalid path [%s]", i, rl.Path)
+			}
+		}
+
+		switch rl.Action {
+		case ActionDrop:
+		case ActionHash:
+			if rl.Salt == "" && cfg.Salt == "" {
+				return nil, errors.Errorf("redaction rule %d: hash requires a salt", i)
+			}
+		case ActionTruncate:
+			if rl.Length <= 0 {
+				return nil, errors.Errorf("redaction rule %d: truncate requires a positive length", i)
+			}
+		default:
+			return nil, errors.Errorf("redaction rule %d: unknown action [%s]", i, rl.Action)
+		}
+
+		redactRule := rule{Rule: rl, segments: segments}
+
+		matched, err := redactRule.checkMessage((&api.Decision{}).ProtoReflect().Descriptor(), 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "redaction rule %d", i)
+		}
+		if matched == 0 {
+			return nil, errors.Errorf("redaction rule %d: path [%s] does not address a field of the decision", i, rl.Path)
+		}
+
+		r.rules = append(r.rules, redactRule)
+	}
+
+	return r, nil
+}
+
+// checkMessage checks the fields of a message of the decision matched by the segment at depth,
+// and returns the number of fields the rule applies to.
+//
+// Redacted decisions are read back into an api.Decision, so a rule may only hash or truncate
+// string fields, and may only drop the elements of a repeated field holding JSON values. The
+// values of google.protobuf.Struct fields, such as the resource, can all be redacted.
+func (rl *rule) checkMessage(md protoreflect.MessageDescriptor, depth int) (int, error) {
+	segment := rl.segments[depth]
+	fields := md.Fields()
+	matched := 0
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if segment != wildcard && segment != string(fd.Name()) {
+			continue
+		}
+
+		n, err := rl.checkNode(fd, false, depth)
+		if err != nil {
+			return 0, err
+		}
+		matched += n
+	}
+
+	return matched, nil
+}
+
+// checkNode checks the value of the field fd, or one of its elements when elem is set, matched
+// by the segment at depth.
+func (rl *rule) checkNode(fd protoreflect.FieldDescriptor, elem bool, depth int) (int, error) {
+	vd := fd
+	if elem && fd.IsMap() {
+		vd = fd.MapValue()
+	}
+	collection := !elem && (fd.IsList() || fd.IsMap())
+
+	if depth == len(rl.segments)-1 {
+		return 1, rl.checkTarget(fd, vd, elem, collection)
+	}
+
+	switch {
+	case collection && fd.IsList():
+		if rl.segments[depth+1] != wildcard {
+			return 0, nil
+		}
+		return rl.checkNode(fd, true, depth+1)
+	case collection:
+		return rl.checkNode(fd, true, depth+1)
+	case vd.Kind() != protoreflect.MessageKind:
+		return 0, nil
+	case isJSON(vd.Message()):
+		return 1, nil
+	default:
+		return rl.checkMessage(vd.Message(), depth+1)
+	}
+}
+
+func (rl *rule) checkTarget(fd, vd protoreflect.FieldDescriptor, elem, collection bool) error {
+	switch rl.Action {
+	case ActionDrop:
+		if elem && fd.IsList() && !isJSONValue(vd) {
+			return errors.Errorf("%s cannot drop the elements of %s", rl.Action, fd.FullName())
+		}
+	case ActionHash, ActionTruncate:
+		if collection || !(vd.Kind() == protoreflect.StringKind || isJSONValue(vd) || isMessage(vd, &wrapperspb.StringValue{})) {
+			return errors.Errorf("%s requires a string field, %s is not", rl.Action, fd.FullName())
+		}
+	}
+	return nil
+}
+
+// isJSON reports whether md is google.protobuf.Struct, Value or ListValue, whose values at any depth are JSON values.
+func isJSON(md protoreflect.MessageDescriptor) bool {
+	return md.ParentFile().Path() == structpb.File_google_protobuf_struct_proto.Path()
+}
+
+// isJSONValue reports whether fd holds any JSON value.
+func isJSONValue(fd protoreflect.FieldDescriptor) bool {
+	return isMessage(fd, &structpb.Value{})
+}
+
+func isMessage(fd protoreflect.FieldDescriptor, m protoreflect.ProtoMessage) bool {
+	return fd.Kind() == protoreflect.MessageKind && fd.Message().FullName() == m.ProtoReflect().Descriptor().FullName()
+}
+
+// Redact returns a copy of the decision with all rules applied. The input decision is not modified.
+func (r *Redactor) Redact(d *api.Decision) (*api.Decision, error) {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(d)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal decision")
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal decision")
+	}
+
+	for i := range r.rules {
+		r.apply(doc, &r.rules[i], 0)
+	}
+
+	if b, err = json.Marshal(doc); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal redacted decision")
+	}
+
+	redacted := &api.Decision{}
+	if err := protojson.Unmarshal(b, redacted); err != nil {
+		return nil, errors.Wrap(err, "redaction produced an invalid decision")
+	}
+
+	return redacted, nil
+}
+
+func (r *Redactor) apply(node interface{}, rl *rule, depth int) {
+	last := depth == len(rl.segments)-1
+	segment := rl.segments[depth]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, v := range n {
+			if segment != wildcard && segment != k {
+				continue
+			}
+			if !last {
+				r.apply(v, rl, depth+1)
+				continue
+			}
+			if rl.Action == ActionDrop {
+				delete(n, k)
+				continue
+			}
+			n[k] = r.transform(v, rl)
+		}
+	case []interface{}:
+		if segment != wildcard {
+			return
+		}
+		for i, v := range n {
+			if !last {
+				r.apply(v, rl, depth+1)
+				continue
+			}
+			// dropped array elements are replaced with null to keep the positions of the others.
+			if rl.Action == ActionDrop {
+				n[i] = nil
+				continue
+			}
+			n[i] = r.transform(v, rl)
+		}
+	}
+}
+
+func (r *Redactor) transform(v interface{}, rl *rule) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		if rl.Action == ActionTruncate {
+			return v
+		}
+		b, _ := json.Marshal(v)
+		s = string(b)
+	}
+
+	switch rl.Action {
+	case ActionHash:
+		salt := rl.Salt
+		if salt == "" {
+			salt = r.salt
+		}
+		mac := hmac.New(sha256.New, []byte(salt))
+		mac.Write([]byte(s))
+		return hashPrefix + hex.EncodeToString(mac.Sum(nil))
+	case ActionTruncate:
+		runes := []rune(s)
+		if len(runes) > rl.Length {
+			return string(runes[:rl.Length])
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,136 @@
+package redact_test
+
+import (
+	"strings"
+	"testing"
+
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/topaz/decision_log/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func decision(t *testing.T) *api.Decision {
+	resource, err := structpb.NewStruct(map[string]interface{}{
+		"owner": map[string]interface{}{"email": "euang@acmecorp.com", "ssn": "123-45-6789"},
+		"items": []interface{}{
+			map[string]interface{}{"ssn": "111-11-1111"},
+			map[string]interface{}{"ssn": "222-22-2222"},
+		},
+	})
+	require.NoError(t, err)
+
+	return &api.Decision{
+		Id: "1",
+		User: &api.DecisionUser{
+			Email: "euang@acmecorp.com",
+			Context: &api.IdentityContext{
+				Identity: "eyJhbGciOiJIUzI1NiJ9.e30.secret",
+				Type:     api.IdentityType_IDENTITY_TYPE_JWT,
+			},
+		},
+		Resource: resource,
+		Outcomes: map[string]bool{"allowed": true},
+	}
+}
+
+func TestRedact(t *testing.T) {
+	r, err := redact.New(&redact.Config{
+		Salt: "pepper",
+		Rules: []redact.Rule{
+			{Path: "user.context.identity", Action: redact.ActionHash},
+			{Path: "$.user.email", Action: redact.ActionTruncate, Length: 5},
+			{Path: "resource.owner.ssn", Action: redact.ActionDrop},
+			{Path: "resource.items.*.ssn", Action: redact.ActionHash, Salt: "salt"},
+		},
+	})
+	require.NoError(t, err)
+
+	d := decision(t)
+	redacted, err := r.Redact(d)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(redacted.User.Context.Identity, "sha256:"))
+	assert.NotContains(t, redacted.User.Context.Identity, "secret")
+	assert.Equal(t, api.IdentityType_IDENTITY_TYPE_JWT, redacted.User.Context.Type)
+	assert.Equal(t, "euang", redacted.User.Email)
+	assert.Equal(t, map[string]bool{"allowed": true}, redacted.Outcomes)
+
+	owner := redacted.Resource.Fields["owner"].GetStructValue().AsMap()
+	assert.Equal(t, map[string]interface{}{"email": "euang@acmecorp.com"}, owner)
+
+	items := redacted.Resource.Fields["items"].GetListValue().AsSlice()
+	require.Len(t, items, 2)
+	first := items[0].(map[string]interface{})["ssn"].(string)
+	second := items[1].(map[string]interface{})["ssn"].(string)
+	assert.True(t, strings.HasPrefix(first, "sha256:"))
+	assert.NotEqual(t, first, second)
+
+	// the input decision is left untouched.
+	assert.Equal(t, "euang@acmecorp.com", d.User.Email)
+	assert.Equal(t, "eyJhbGciOiJIUzI1NiJ9.e30.secret", d.User.Context.Identity)
+
+	// hashing is deterministic for a given salt.
+	again, err := r.Redact(decision(t))
+	require.NoError(t, err)
+	assert.Equal(t, redacted.User.Context.Identity, again.User.Context.Identity)
+}
+
+func TestRedactTypedFields(t *testing.T) {
+	r, err := redact.New(&redact.Config{
+		Salt: "pepper",
+		Rules: []redact.Rule{
+			{Path: "outcomes.allowed", Action: redact.ActionDrop},
+			{Path: "timestamp", Action: redact.ActionDrop},
+			{Path: "annotations.*", Action: redact.ActionHash},
+			{Path: "policy.context.decisions.*", Action: redact.ActionTruncate, Length: 3},
+			{Path: "resource.*", Action: redact.ActionHash},
+		},
+	})
+	require.NoError(t, err)
+
+	d := decision(t)
+	d.Timestamp = timestamppb.Now()
+	d.Annotations = map[string]string{"trace": "abc"}
+	d.Policy = &api.DecisionPolicy{Context: &api.PolicyContext{Path: "todo", Decisions: []string{"allowed"}}}
+
+	redacted, err := r.Redact(d)
+	require.NoError(t, err)
+
+	assert.Empty(t, redacted.Outcomes)
+	assert.Nil(t, redacted.Timestamp)
+	assert.True(t, strings.HasPrefix(redacted.Annotations["trace"], "sha256:"))
+	assert.Equal(t, []string{"all"}, redacted.Policy.Context.Decisions)
+	assert.True(t, strings.HasPrefix(redacted.Resource.Fields["owner"].GetStringValue(), "sha256:"))
+}
+
+func TestRedactInvalidRules(t *testing.T) {
+	tests := map[string]redact.Rule{
+		"empty path":      {Action: redact.ActionDrop},
+		"invalid path":    {Path: "user..email", Action: redact.ActionDrop},
+		"unknown action":  {Path: "user.email", Action: "encrypt"},
+		"hash no salt":    {Path: "user.email", Action: redact.ActionHash},
+		"truncate length": {Path: "user.email", Action: redact.ActionTruncate},
+		"unknown field":   {Path: "user.name", Action: redact.ActionDrop},
+		"past a string":   {Path: "user.email.domain", Action: redact.ActionDrop},
+		"hash bool":       {Path: "outcomes.*", Action: redact.ActionHash, Salt: "salt"},
+		"hash enum":       {Path: "user.context.type", Action: redact.ActionHash, Salt: "salt"},
+		"hash message":    {Path: "user.*", Action: redact.ActionHash, Salt: "salt"},
+		"hash struct":     {Path: "resource", Action: redact.ActionHash, Salt: "salt"},
+		"truncate time":   {Path: "timestamp", Action: redact.ActionTruncate, Length: 4},
+		"drop element":    {Path: "policy.context.decisions.*", Action: redact.ActionDrop},
+	}
+
+	for name, rule := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := redact.New(&redact.Config{Rules: []redact.Rule{rule}})
+			assert.Error(t, err)
+		})
+	}
+
+	r, err := redact.New(&redact.Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+}
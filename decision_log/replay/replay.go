@@ -0,0 +1,140 @@
+package replay
+
+import (
+	"context"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/header"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	decisionlog_plugin "github.com/aserto-dev/topaz/decision_log/plugin"
+	"github.com/pkg/errors"
+)
+
+// ErrNotReplayable is returned for records that do not hold an is call, such as
+// decision tree records or hash chain checkpoints.
+var ErrNotReplayable = errors.New("decision record cannot be replayed")
+
+// Authorizer evaluates is calls against the candidate policy.
+type Authorizer interface {
+	Is(ctx context.Context, req *authorizer.IsRequest) (*authorizer.IsResponse, error)
+}
+
+// Change is a recorded decision whose outcomes differ when replayed.
+type Change struct {
+	Decision *api.Decision
+	// Outcomes are the outcomes of the replayed decision.
+	Outcomes map[string]bool
+}
+
+// From returns the recorded outcome of the decision, allow or deny.
+func (c *Change) From() string {
+	return decisionlog.Outcome(c.Decision)
+}
+
+// To returns the replayed outcome of the decision, allow or deny.
+func (c *Change) To() string {
+	return decisionlog.Outcome(&api.Decision{Outcomes: c.Outcomes})
+}
+
+// Failure is a recorded decision that could not be evaluated against the candidate policy.
+type Failure struct {
+	Decision *api.Decision
+	Err      error
+}
+
+type Report struct {
+	// Total is the number of decision records read.
+	Total int
+	// Skipped is the number of records that cannot be replayed.
+	Skipped int
+	// Replayed is the number of decisions evaluated against the candidate policy.
+	Replayed int
+	Changes  []*Change
+	Failures []*Failure
+}
+
+// Count returns the number of changes from one outcome to another, e.g. allow to deny.
+func (r *Report) Count(from, to string) int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.From() == from && c.To() == to {
+			n++
+		}
+	}
+	return n
+}
+
+// Replayer evaluates recorded decisions again and collects the ones whose outcomes change.
+type Replayer struct {
+	authorizer Authorizer
+	report     Report
+}
+
+func New(az Authorizer) *Replayer {
+	return &Replayer{authorizer: az}
+}
+
+// Replay evaluates the recorded decision against the candidate policy.
+func (r *Replayer) Replay(ctx context.Context, d *api.Decision) {
+	r.report.Total++
+
+	req, err := Request(d)
+	if err != nil {
+		r.report.Skipped++
+		return
+	}
+
+	if tenantID := d.GetTenantId(); tenantID != "" {
+		ctx = context.WithValue(ctx, header.HeaderAsertoTenantID, tenantID)
+	}
+
+	resp, err := r.authorizer.Is(ctx, req)
+	if err != nil {
+		r.report.Failures = append(r.report.Failures, &Failure{Decision: d, Err: err})
+		return
+	}
+	r.report.Replayed++
+
+	outcomes := make(map[string]bool, len(resp.Decisions))
+	changed := false
+	for _, o := range resp.Decisions {
+		outcomes[o.Decision] = o.Is
+		if recorded, ok := d.Outcomes[o.Decision]; !ok || recorded != o.Is {
+			changed = true
+		}
+	}
+
+	if changed {
+		r.report.Changes = append(r.report.Changes, &Change{Decision: d, Outcomes: outcomes})
+	}
+}
+
+func (r *Replayer) Report() *Report {
+	return &r.report
+}
+
+// Request rebuilds the is request of a decision record from its recorded identity,
+// resource and policy contexts.
+func Request(d *api.Decision) (*authorizer.IsRequest, error) {
+	if a, ok := d.Annotations[decisionlog.AnnotationAPI]; ok && a != decisionlog_plugin.APIIs {
+		return nil, ErrNotReplayable
+	}
+
+	policyContext := d.GetPolicy().GetContext()
+	if policyContext == nil || policyContext.Path == "" || len(policyContext.Decisions) == 0 {
+		return nil, ErrNotReplayable
+	}
+
+	identityContext := d.GetUser().GetContext()
+	if identityContext == nil {
+		return nil, ErrNotReplayable
+	}
+
+	return &authorizer.IsRequest{
+		PolicyContext:   policyContext,
+		IdentityContext: identityContext,
+		ResourceContext: d.Resource,
+		PolicyInstance:  d.GetPolicy().GetPolicyInstance(),
+	}, nil
+}
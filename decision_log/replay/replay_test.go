@@ -0,0 +1,86 @@
+package replay_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	api "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/header"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/replay"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// candidate denies everything to bob, and fails for unknown users.
+type candidate struct {
+	tenants []string
+}
+
+func (c *candidate) Is(ctx context.Context, req *authorizer.IsRequest) (*authorizer.IsResponse, error) {
+	c.tenants = append(c.tenants, header.ExtractTenantID(ctx))
+
+	if req.IdentityContext.Identity == "eve" {
+		return nil, errors.New("user not found")
+	}
+
+	resp := &authorizer.IsResponse{}
+	for _, d := range req.PolicyContext.Decisions {
+		resp.Decisions = append(resp.Decisions, &authorizer.Decision{Decision: d, Is: req.IdentityContext.Identity != "bob"})
+	}
+	return resp, nil
+}
+
+func decision(id, identity string, allowed bool) *api.Decision {
+	return &api.Decision{
+		Id:   id,
+		Path: "peoplefinder.GET.users",
+		Policy: &api.DecisionPolicy{
+			Context: &api.PolicyContext{Path: "peoplefinder.GET.users", Decisions: []string{"allowed"}},
+		},
+		User: &api.DecisionUser{
+			Context: &api.IdentityContext{Identity: identity, Type: api.IdentityType_IDENTITY_TYPE_SUB},
+		},
+		TenantId:    proto.String("acme"),
+		Outcomes:    map[string]bool{"allowed": allowed},
+		Annotations: map[string]string{decisionlog.AnnotationAPI: "is"},
+	}
+}
+
+func TestReplay(t *testing.T) {
+	az := &candidate{}
+	r := replay.New(az)
+
+	tree := decision("5", "alice", true)
+	tree.Annotations[decisionlog.AnnotationAPI] = "decision_tree"
+
+	for _, d := range []*api.Decision{
+		decision("1", "alice", true),
+		decision("2", "bob", true),
+		decision("3", "alice", false),
+		decision("4", "eve", true),
+		tree,
+		{Id: "checkpoint"},
+	} {
+		r.Replay(context.Background(), d)
+	}
+
+	report := r.Report()
+	assert.Equal(t, 6, report.Total)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 3, report.Replayed)
+
+	require.Len(t, report.Changes, 2)
+	assert.Equal(t, "2", report.Changes[0].Decision.Id)
+	assert.Equal(t, map[string]bool{"allowed": false}, report.Changes[0].Outcomes)
+	assert.Equal(t, 1, report.Count(decisionlog.OutcomeAllow, decisionlog.OutcomeDeny))
+	assert.Equal(t, 1, report.Count(decisionlog.OutcomeDeny, decisionlog.OutcomeAllow))
+
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, "4", report.Failures[0].Decision.Id)
+
+	assert.Equal(t, []string{"acme", "acme", "acme", "acme"}, az.tenants)
+}
@@ -27,6 +27,12 @@ func Generate() error {
 	return common.Generate()
 }
 
+// GenerateProto generates the code of the protobuf definitions in ./api with the
+// plugin versions pinned in buf.gen.yaml.
+func GenerateProto() error {
+	return deps.GoDep("buf")("generate")
+}
+
 // Build builds all binaries in ./cmd.
 func Build() error {
 	return common.BuildReleaser()
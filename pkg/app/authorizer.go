@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	edgeServer "github.com/aserto-dev/go-edge-ds/pkg/server"
+	"github.com/aserto-dev/topaz/pkg/app/directory"
 	"github.com/aserto-dev/topaz/pkg/app/server"
 	"github.com/aserto-dev/topaz/pkg/cc/config"
 	"github.com/aserto-dev/topaz/resolvers"
@@ -49,6 +50,19 @@ func (e *Authorizer) Start() error {
 		}
 
 		e.Server.RegisterServer("edgeDirServer", edge.Start, edge.Stop)
+
+		// decision watches are notified of the writes made to the edge directory, the edge
+		// server creates its database file.
+		changes := directory.NewChanges()
+
+		watcher, err := directory.NewDBWatcher(e.Configuration.Directory.EdgeConfig.DBPath, changes, e.Logger)
+		if err != nil {
+			return err
+		}
+
+		e.Resolver.SetDirectoryWatcher(changes)
+
+		e.Server.RegisterServer("edgeDirWatcher", watcher.Start, watcher.Stop)
 	}
 
 	err := e.Server.Start(e.Context)
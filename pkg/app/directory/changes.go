@@ -0,0 +1,48 @@
+package directory
+
+import (
+	"sync"
+
+	"github.com/aserto-dev/topaz/resolvers"
+)
+
+// Changes broadcasts the changes made to the directory to its watchers.
+type Changes struct {
+	mu       sync.Mutex
+	watchers map[chan struct{}]struct{}
+}
+
+var _ resolvers.DirectoryWatcher = &Changes{}
+
+func NewChanges() *Changes {
+	return &Changes{watchers: map[chan struct{}]struct{}{}}
+}
+
+// Watch returns a channel that receives a value after the directory changes, and a function that ends the watch.
+func (c *Changes) Watch() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	c.mu.Lock()
+	c.watchers[ch] = struct{}{}
+	c.mu.Unlock()
+
+	return ch, func() {
+		c.mu.Lock()
+		delete(c.watchers, ch)
+		c.mu.Unlock()
+	}
+}
+
+// Notify signals a change to the watchers. Watchers that have not received the previous change yet
+// are not signaled again.
+func (c *Changes) Notify() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for ch := range c.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
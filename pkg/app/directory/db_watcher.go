@@ -0,0 +1,77 @@
+package directory
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// settleDelay is how long the database file must go unwritten before a change is notified. A
+// transaction writes its pages before its meta page, which makes it visible to readers.
+const settleDelay = 50 * time.Millisecond
+
+// DBWatcher notifies changes every time the database file of the edge directory is written,
+// which happens on every committed write transaction, whether it is made through the writer
+// or the importer service. Reads are served from a memory map, they are not notified.
+type DBWatcher struct {
+	watcher *fsnotify.Watcher
+	changes *Changes
+	logger  *zerolog.Logger
+}
+
+// NewDBWatcher watches the database file at path, which must exist.
+func NewDBWatcher(path string, changes *Changes, logger *zerolog.Logger) (*DBWatcher, error) {
+	watcherLogger := logger.With().Str("component", "edge-directory-watcher").Logger()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create edge directory watcher")
+	}
+
+	if err := watcher.Add(path); err != nil {
+		_ = watcher.Close()
+		return nil, errors.Wrapf(err, "failed to watch edge directory database [%s]", path)
+	}
+
+	return &DBWatcher{
+		watcher: watcher,
+		changes: changes,
+		logger:  &watcherLogger,
+	}, nil
+}
+
+// Start notifies the changes to the database file until ctx is done or the watcher is stopped.
+// The writes of a transaction are notified once, after the database file settles.
+func (w *DBWatcher) Start(ctx context.Context) error {
+	settled := time.NewTimer(settleDelay)
+	settled.Stop()
+	defer settled.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-settled.C:
+			w.changes.Notify()
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Write) {
+				settled.Reset(settleDelay)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.logger.Error().Err(err).Msg("failed to watch edge directory database")
+		}
+	}
+}
+
+func (w *DBWatcher) Stop(ctx context.Context) error {
+	return w.watcher.Close()
+}
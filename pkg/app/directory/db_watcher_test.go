@@ -0,0 +1,49 @@
+package directory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eds.db")
+	require.NoError(t, os.WriteFile(path, []byte("v1"), 0o600))
+
+	changes := NewChanges()
+	ch, stop := changes.Watch()
+	defer stop()
+
+	logger := zerolog.Nop()
+	w, err := NewDBWatcher(path, changes, &logger)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() { done <- w.Start(ctx) }()
+
+	require.NoError(t, os.WriteFile(path, []byte("v2"), 0o600))
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("write to the database was not notified")
+	}
+
+	require.NoError(t, w.Stop(ctx))
+	assert.NoError(t, <-done)
+}
+
+func TestDBWatcherMissingFile(t *testing.T) {
+	logger := zerolog.Nop()
+	_, err := NewDBWatcher(filepath.Join(t.TempDir(), "eds.db"), NewChanges(), &logger)
+	assert.Error(t, err)
+}
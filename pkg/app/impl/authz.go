@@ -14,25 +14,27 @@ import (
 	"github.com/aserto-dev/header"
 
 	runtime "github.com/aserto-dev/runtime"
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
 	decisionlog_plugin "github.com/aserto-dev/topaz/decision_log/plugin"
 
 	"github.com/aserto-dev/topaz/pkg/cc/config"
 	"github.com/aserto-dev/topaz/pkg/version"
 	"github.com/aserto-dev/topaz/resolvers"
-	"github.com/google/uuid"
+	"github.com/gobwas/glob"
 	"github.com/mennanov/fmutils"
+	"github.com/open-policy-agent/opa/plugins"
 	"github.com/open-policy-agent/opa/rego"
 	"github.com/open-policy-agent/opa/server/types"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/structpb"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -46,21 +48,51 @@ type AuthorizerServer struct {
 	cfg    *config.Common
 	logger *zerolog.Logger
 
-	resolver *resolvers.Resolvers
+	resolver  *resolvers.Resolvers
+	queries   *queryCache
+	decisions *decisionCache
+	limits    *evalLimits
+	bundles   *bundleWatchers
 }
 
 func NewAuthorizerServer(
 	logger *zerolog.Logger,
 	cfg *config.Common,
 	rf *resolvers.Resolvers,
-) *AuthorizerServer {
+) (*AuthorizerServer, error) {
 	newLogger := logger.With().Str("component", "api.grpc").Logger()
 
-	return &AuthorizerServer{
-		cfg:      cfg,
-		logger:   &newLogger,
-		resolver: rf,
+	decisions, err := newDecisionCache(&cfg.Authorizer.DecisionCache)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := newEvalLimits(&cfg.Authorizer.EvalLimits)
+	if err != nil {
+		return nil, err
 	}
+
+	s := &AuthorizerServer{
+		cfg:       cfg,
+		logger:    &newLogger,
+		resolver:  rf,
+		queries:   newQueryCache(cfg.Authorizer.QueryCache.Size),
+		decisions: decisions,
+		limits:    limits,
+		bundles:   newBundleWatchers(),
+	}
+
+	rf.AddRuntimeHook(s.registerCompilerTriggers)
+
+	return s, nil
+}
+
+// registerCompilerTriggers registers the triggers that drop the queries and decisions cached
+// for the plugins manager of a runtime, and notify its watches, when one of its bundles activates.
+func (s *AuthorizerServer) registerCompilerTriggers(m *plugins.Manager) {
+	s.queries.register(m)
+	s.decisions.register(m)
+	s.bundles.register(m)
 }
 
 func (s *AuthorizerServer) DecisionTree(ctx context.Context, req *authorizer.DecisionTreeRequest) (*authorizer.DecisionTreeResponse, error) { // nolint:funlen,gocyclo //TODO: split into smaller functions after merge with onebox
@@ -129,58 +161,58 @@ func (s *AuthorizerServer) DecisionTree(ctx context.Context, req *authorizer.Dec
 		return resp, errors.Wrap(err, "get policy list")
 	}
 
-	decisionFilter := initDecisionFilter(req.PolicyContext.Decisions)
-
-	results := make(map[string]interface{})
+	opts, err := s.treeOptions(ctx, req.PolicyContext.Decisions, policyList)
+	if err != nil {
+		return resp, err
+	}
 
-	policyContext := proto.Clone(req.PolicyContext).(*api.PolicyContext)
+	evalCtx, calls := ds.WithCalls(ctx, recordDSCalls(policyRuntime, decisionlog_plugin.APIDecisionTree))
+	start := time.Now()
 
-	for _, policy := range policyList {
-		queryStmt := "x = data." + policy.PackageName
+	packageResults := s.evalDecisionTree(evalCtx, policyRuntime.GetPluginsManager(), policyList, req.PolicyContext, input, opts)
 
-		policyContext.Path = policy.PackageName
-		input[InputPolicy] = policyContext
+	elapsed := time.Since(start)
 
-		qry, err := rego.New(
-			rego.Compiler(policyRuntime.GetPluginsManager().GetCompiler()),
-			rego.Store(policyRuntime.GetPluginsManager().Store),
-			rego.Input(input),
-			rego.Query(queryStmt),
-		).PrepareForEval(ctx)
+	if err := ctx.Err(); err != nil {
+		return resp, status.FromContextError(err).Err()
+	}
 
-		if err != nil {
-			return resp, aerr.ErrBadQuery.Err(err).Str("query", queryStmt)
-		}
+	results := make(map[string]interface{})
+	outcomes := make(map[string]bool)
+	errs := make(map[string]string)
 
-		packageName := getPackageName(policy, req.Options.PathSeparator)
+	var firstErr error
 
-		queryResults, err := qry.Eval(ctx, rego.EvalInput(input))
+	for i, result := range packageResults {
+		if result.err != nil {
+			log.Warn().Err(result.err).Str("package", policyList[i].PackageName).Msg("failed to evaluate package")
+			errs[policyList[i].PackageName] = result.err.Error()
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			continue
+		}
 
-		if err != nil {
-			return resp, aerr.ErrBadQuery.Err(err).Str("query", queryStmt).Msg("query evaluation failed")
-		} else if len(queryResults) == 0 {
-			return resp, aerr.ErrBadQuery.Err(err).Str("query", queryStmt).Msg("undefined results")
+		for k, v := range result.outcomes {
+			outcomes[k] = v
 		}
+		if len(result.decisions) > 0 {
+			results[getPackageName(policyList[i], req.Options.PathSeparator)] = result.decisions
+		}
+	}
 
-		if result, ok := queryResults[0].Bindings["x"].(map[string]interface{}); ok {
-			for k, v := range result {
-				if !decisionFilter(k) {
-					continue
-				}
-				if _, ok := v.(bool); !ok {
-					continue
-				}
-				if results[packageName] == nil {
-					results[packageName] = make(map[string]interface{})
-				}
-				if r, ok := results[packageName].(map[string]interface{}); ok {
-					r[k] = v
-				}
-			}
+	// a tree none of whose packages evaluate fails as a whole.
+	if len(errs) > 0 && len(errs) == len(policyList) {
+		return resp, firstErr
+	}
+
+	if len(errs) > 0 {
+		if err := setDecisionTreeErrors(ctx, errs); err != nil {
+			log.Debug().Err(err).Msg("failed to set decision tree errors header")
 		}
 	}
 
-	paths, err := structpb.NewStruct(results)
+	paths, err := toStruct(results)
 	if err != nil {
 		return resp, err
 	}
@@ -190,89 +222,188 @@ func (s *AuthorizerServer) DecisionTree(ctx context.Context, req *authorizer.Dec
 		Path:     paths,
 	}
 
+	if dlPlugin := decisionLogger(policyRuntime, decisionlog_plugin.APIDecisionTree); dlPlugin != nil {
+		d := newDecision(ctx, decisionlog_plugin.APIDecisionTree, req.PolicyContext.Path,
+			req.PolicyContext, req.PolicyInstance, req.IdentityContext, req.ResourceContext, input, outcomes)
+		annotateEval(d, elapsed, calls)
+		annotateErrors(d, errs)
+
+		dlPlugin.Log(ctx, d)
+	}
+
 	return resp, nil
 }
 
 // Is decision eval function.
-func (s *AuthorizerServer) Is(ctx context.Context, req *authorizer.IsRequest) (*authorizer.IsResponse, error) { // nolint:funlen,gocyclo //TODO: split into smaller functions after merge with onebox
-	log := s.logger.With().Str("api", "is").Logger()
-
+func (s *AuthorizerServer) Is(ctx context.Context, req *authorizer.IsRequest) (*authorizer.IsResponse, error) {
 	resp := &authorizer.IsResponse{
 		Decisions: make([]*authorizer.Decision, 0),
 	}
 
+	result, err := s.is(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Decisions = result.decisions
+
+	return resp, nil
+}
+
+// is evaluates, or looks up in the decision cache, the decisions of an Is call and logs them.
+func (s *AuthorizerServer) is(ctx context.Context, req *authorizer.IsRequest) (*isResult, error) { // nolint:funlen,gocyclo //TODO: split into smaller functions after merge with onebox
+	log := s.logger.With().Str("api", "is").Logger()
+
+	input, err := s.isInput(ctx, req, &log)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().Interface("input", input).Msg("calculating is")
+
+	policyRuntime, err := s.getRuntime(ctx, req.PolicyInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	evalCtx, calls := ds.WithCalls(ctx, recordDSCalls(policyRuntime, decisionlog_plugin.APIIs))
+
+	var (
+		result  *isResult
+		elapsed time.Duration
+	)
+
+	cached, ticket := s.decisions.lookup(policyRuntime.GetPluginsManager(), newDecisionKey(ctx, req, input))
+	if cached != nil {
+		result = &cached.isResult
+	} else {
+		start := time.Now()
+
+		query, err := s.prepareIs(evalCtx, policyRuntime, req.PolicyContext.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		limitCtx, limit, cancel := s.limits.limit(evalCtx, decisionlog_plugin.APIIs, req.PolicyContext.Path)
+		result, err = evalIs(limitCtx, query, req.PolicyContext.Path, input, req.PolicyContext.Decisions)
+		cancel()
+		elapsed = time.Since(start)
+
+		err = limit.err(err)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.decisions.store(ticket, result)
+	}
+
+	dlPlugin := decisionLogger(policyRuntime, decisionlog_plugin.APIIs)
+	if dlPlugin == nil {
+		return result, nil
+	}
+
+	d := newDecision(ctx, decisionlog_plugin.APIIs, req.PolicyContext.Path,
+		req.PolicyContext, req.PolicyInstance, req.IdentityContext, req.ResourceContext, input, result.outcomes)
+	if cached != nil {
+		annotateCached(d)
+	} else {
+		annotateEval(d, elapsed, calls)
+	}
+	annotateCompanions(d, result.companions)
+
+	dlPlugin.Log(ctx, d)
+
+	return result, nil
+}
+
+// isInput validates an Is request, resolves its identity, and returns the input its decisions are evaluated with.
+func (s *AuthorizerServer) isInput(ctx context.Context, req *authorizer.IsRequest, log *zerolog.Logger) (map[string]interface{}, error) {
 	if req.PolicyContext == nil {
-		return resp, aerr.ErrInvalidArgument.Msg("policy context not set")
+		return nil, aerr.ErrInvalidArgument.Msg("policy context not set")
 	}
 
 	if req.PolicyContext.Path == "" {
-		return resp, aerr.ErrInvalidArgument.Msg("policy context path not set")
+		return nil, aerr.ErrInvalidArgument.Msg("policy context path not set")
 	}
 
 	if len(req.PolicyContext.Decisions) == 0 {
-		return resp, aerr.ErrInvalidArgument.Msg("policy context decisions not set")
+		return nil, aerr.ErrInvalidArgument.Msg("policy context decisions not set")
 	}
 
 	if req.ResourceContext == nil {
 		var err error
 		req.ResourceContext, err = structpb.NewStruct(make(map[string]interface{}))
 		if err != nil {
-			return resp, err
+			return nil, err
 		}
 	}
 
 	if req.IdentityContext == nil {
-		return resp, aerr.ErrInvalidArgument.Msg("identity context not set")
+		return nil, aerr.ErrInvalidArgument.Msg("identity context not set")
 	}
 
 	if req.IdentityContext.Type == api.IdentityType_IDENTITY_TYPE_UNKNOWN {
-		return resp, aerr.ErrInvalidArgument.Msg("identity type UNKNOWN")
+		return nil, aerr.ErrInvalidArgument.Msg("identity type UNKNOWN")
 	}
 
 	user, err := s.getUserFromIdentityContext(ctx, req.IdentityContext)
 	if err != nil {
 		log.Error().Err(err).Interface("req", req).Msg("failed to resolve identity context")
-		return resp, aerr.ErrUserNotFound.WithGRPCStatus(codes.NotFound).Msg("failed to resolve identity context")
+		return nil, aerr.ErrUserNotFound.WithGRPCStatus(codes.NotFound).Msg("failed to resolve identity context")
 	}
 
-	input := map[string]interface{}{
+	return map[string]interface{}{
 		InputUser:     convert(user),
 		InputIdentity: convert(req.IdentityContext),
 		InputPolicy:   req.PolicyContext,
 		InputResource: req.ResourceContext,
-	}
+	}, nil
+}
 
-	log.Debug().Interface("input", input).Msg("calculating is")
+// prepareIs returns the prepared query evaluating the decisions of the policy at path.
+func (s *AuthorizerServer) prepareIs(ctx context.Context, rt *runtime.Runtime, path string) (*rego.PreparedEvalQuery, error) {
+	queryStmt := fmt.Sprintf("x = data.%s", path)
 
-	policyRuntime, err := s.getRuntime(ctx, req.PolicyInstance)
+	query, err := s.queries.prepare(ctx, rt.GetPluginsManager(), queryStmt)
 	if err != nil {
-		return resp, err
+		return nil, aerr.ErrBadQuery.Err(err).Str("query", queryStmt)
 	}
 
-	queryStmt := fmt.Sprintf("x = data.%s", req.PolicyContext.Path)
-
-	query, err := rego.New(
-		rego.Compiler(policyRuntime.GetPluginsManager().GetCompiler()),
-		rego.Store(policyRuntime.GetPluginsManager().Store),
-		rego.Query(queryStmt),
-	).PrepareForEval(ctx)
-
-	if err != nil {
-		return resp, aerr.ErrBadQuery.Err(err).Str("query", queryStmt)
-	}
+	return query, nil
+}
 
-	results, err := query.Eval(ctx, rego.EvalInput(input))
+// isResult holds the decisions of an Is evaluation, their outcomes by decision, and the
+// reasons and obligations the policy supplied alongside them.
+type isResult struct {
+	decisions  []*authorizer.Decision
+	outcomes   map[string]bool
+	companions *companions
+}
 
+// evalIs evaluates the prepared query of the policy at path and returns the outcome of each of the decisions.
+func evalIs(
+	ctx context.Context,
+	query *rego.PreparedEvalQuery,
+	path string,
+	input map[string]interface{},
+	decisions []string,
+	opts ...rego.EvalOption,
+) (*isResult, error) {
+	queryStmt := fmt.Sprintf("x = data.%s", path)
+
+	results, err := query.Eval(ctx, append([]rego.EvalOption{rego.EvalInput(input)}, opts...)...)
 	if err != nil {
-		return resp, aerr.ErrBadQuery.Err(err).Str("query", queryStmt).Msg("query evaluation failed")
+		return nil, aerr.ErrBadQuery.Err(err).Str("query", queryStmt).Msg("query evaluation failed")
 	} else if len(results) == 0 {
-		return resp, aerr.ErrBadQuery.Err(err).Str("query", queryStmt).Msg("undefined results")
+		return nil, aerr.ErrBadQuery.Err(err).Str("query", queryStmt).Msg("undefined results")
 	}
 
 	v := results[0].Bindings["x"]
+	result := make([]*authorizer.Decision, 0, len(decisions))
 	outcomes := map[string]bool{}
 
-	for _, d := range req.PolicyContext.Decisions {
+	for _, d := range decisions {
 		decision := authorizer.Decision{
 			Decision: d,
 		}
@@ -280,40 +411,15 @@ func (s *AuthorizerServer) Is(ctx context.Context, req *authorizer.IsRequest) (*
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed getting outcome for decision [%s]", d)
 		}
-		resp.Decisions = append(resp.Decisions, &decision)
+		result = append(result, &decision)
 		outcomes[decision.Decision] = decision.Is
 	}
 
-	dlPlugin := decisionlog_plugin.Lookup(policyRuntime.GetPluginsManager())
-	tenantID := getTenantID(ctx)
-	d := api.Decision{
-		Id:        uuid.NewString(),
-		Timestamp: timestamppb.New(time.Now().In(time.UTC)),
-		Path:      req.PolicyContext.Path,
-		Policy: &api.DecisionPolicy{
-			Context:        req.PolicyContext,
-			PolicyInstance: req.PolicyInstance,
-		},
-		User: &api.DecisionUser{
-			Context: req.IdentityContext,
-			Id:      getID(input),
-			Email:   getEmail(input),
-		},
-		TenantId: tenantID,
-		Resource: req.ResourceContext,
-		Outcomes: outcomes,
-	}
-
-	if dlPlugin == nil {
-		return resp, err
-	}
-
-	err = dlPlugin.Log(ctx, &d)
-	if err != nil {
-		return resp, err
-	}
-
-	return resp, err
+	return &isResult{
+		decisions:  result,
+		outcomes:   outcomes,
+		companions: companionsOf(v, decisions),
+	}, nil
 }
 
 func getTenantID(ctx context.Context) *string {
@@ -420,8 +526,14 @@ func (s *AuthorizerServer) Query(ctx context.Context, req *authorizer.QueryReque
 		return &authorizer.QueryResponse{}, aerr.ErrBadQuery.Err(err)
 	}
 
+	evalCtx, calls := ds.WithCalls(ctx, recordDSCalls(rt, decisionlog_plugin.APIQuery))
+	start := time.Now()
+
+	limitCtx, limit, cancel := s.limits.limit(evalCtx, decisionlog_plugin.APIQuery, req.GetPolicyContext().GetPath())
+	defer cancel()
+
 	queryResult, err := rt.Query(
-		ctx,
+		limitCtx,
 		req.Query,
 		input,
 		req.Options.TraceSummary,
@@ -429,8 +541,9 @@ func (s *AuthorizerServer) Query(ctx context.Context, req *authorizer.QueryReque
 		req.Options.Instrument,
 		TraceLevelToExplainModeV2(req.Options.Trace),
 	)
+	elapsed := time.Since(start)
 	if err != nil {
-		return &authorizer.QueryResponse{}, err
+		return &authorizer.QueryResponse{}, limit.err(err)
 	}
 
 	resp := &authorizer.QueryResponse{}
@@ -460,6 +573,15 @@ func (s *AuthorizerServer) Query(ctx context.Context, req *authorizer.QueryReque
 		resp.Metrics, _ = structpb.NewStruct(make(map[string]interface{}))
 	}
 
+	if dlPlugin := decisionLogger(rt, decisionlog_plugin.APIQuery); dlPlugin != nil {
+		d := newDecision(ctx, decisionlog_plugin.APIQuery, req.GetPolicyContext().GetPath(),
+			req.PolicyContext, req.PolicyInstance, req.IdentityContext, req.ResourceContext, input, nil)
+		annotateResult(d, req.Query, queryResultMap)
+		annotateEval(d, elapsed, calls)
+
+		dlPlugin.Log(ctx, d)
+	}
+
 	// trace (explanation)
 	if queryResult.Explanation != nil {
 		var v []interface{}
@@ -580,7 +702,10 @@ func (s *AuthorizerServer) Compile(ctx context.Context, req *authorizer.CompileR
 		return &authorizer.CompileResponse{}, aerr.ErrBadQuery.Err(err)
 	}
 
-	compileResult, err := rt.Compile(ctx, req.Query,
+	evalCtx, calls := ds.WithCalls(ctx, recordDSCalls(rt, decisionlog_plugin.APICompile))
+	start := time.Now()
+
+	compileResult, err := rt.Compile(evalCtx, req.Query,
 		input,
 		req.Unknowns,
 		req.DisableInlining,
@@ -588,6 +713,7 @@ func (s *AuthorizerServer) Compile(ctx context.Context, req *authorizer.CompileR
 		req.Options.Metrics,
 		req.Options.Instrument,
 		TraceLevelToExplainModeV2(req.Options.Trace))
+	elapsed := time.Since(start)
 	resp := &authorizer.CompileResponse{}
 	if err != nil {
 		return resp, err
@@ -615,6 +741,15 @@ func (s *AuthorizerServer) Compile(ctx context.Context, req *authorizer.CompileR
 		resp.Metrics, _ = structpb.NewStruct(make(map[string]interface{}))
 	}
 
+	if dlPlugin := decisionLogger(rt, decisionlog_plugin.APICompile); dlPlugin != nil {
+		d := newDecision(ctx, decisionlog_plugin.APICompile, req.GetPolicyContext().GetPath(),
+			req.PolicyContext, req.PolicyInstance, req.IdentityContext, req.ResourceContext, input, nil)
+		annotateResult(d, req.Query, compileResultMap)
+		annotateEval(d, elapsed, calls)
+
+		dlPlugin.Log(ctx, d)
+	}
+
 	// trace (explanation)
 	if compileResult.Explanation != nil {
 		var v []interface{}
@@ -820,22 +955,43 @@ func getPackageName(policy runtime.Policy, sep authorizer.PathSeparator) string
 	}
 }
 
-func initDecisionFilter(decisions []string) func(decision string) bool {
+// initDecisionFilter returns a filter matching the decisions of a decision tree. The decisions are
+// globs on the path of the decision in its package, where * matches one path segment and ** any
+// number of segments. A single "*" decision matches every decision.
+func initDecisionFilter(decisions []string) (func(decision string) bool, error) {
 	if len(decisions) == 1 && decisions[0] == "*" {
 		return func(s string) bool {
 			return true
-		}
+		}, nil
 	}
 
 	decisionMap := make(map[string]struct{})
+	globs := []glob.Glob{}
+
 	for _, v := range decisions {
-		decisionMap[v] = struct{}{}
+		if glob.QuoteMeta(v) == v {
+			decisionMap[v] = struct{}{}
+			continue
+		}
+
+		g, err := glob.Compile(v, '.')
+		if err != nil {
+			return nil, aerr.ErrInvalidArgument.Err(err).Msgf("invalid decision filter [%s]", v)
+		}
+		globs = append(globs, g)
 	}
 
 	return func(s string) bool {
-		_, ok := decisionMap[s]
-		return ok
-	}
+		if _, ok := decisionMap[s]; ok {
+			return true
+		}
+		for _, g := range globs {
+			if g.Match(s) {
+				return true
+			}
+		}
+		return false
+	}, nil
 }
 
 func getID(v map[string]interface{}) string {
@@ -0,0 +1,93 @@
+package impl
+
+import (
+	"context"
+
+	authorizer "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ReasonsSuffix is appended to the name of a decision to name the rule holding its reasons,
+	// such as allowed_reasons for the allowed decision.
+	ReasonsSuffix = "_reasons"
+	// ObligationsRule is the name of the rule holding the obligations of the decisions of a policy.
+	ObligationsRule = "obligations"
+)
+
+// IsWithCompanions evaluates the decisions of an Is call, as Is does, and returns the reasons
+// and obligations the policy supplied alongside them.
+func (s *AuthorizerServer) IsWithCompanions(ctx context.Context, req *authorizer.IsRequest) (*topazauthz.IsWithCompanionsResponse, error) {
+	resp := &topazauthz.IsWithCompanionsResponse{
+		Decisions: make([]*authorizer.Decision, 0),
+	}
+
+	result, err := s.is(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Decisions = result.decisions
+	resp.Reasons = result.companions.reasons()
+	resp.Obligations = result.companions.obligations()
+
+	return resp, nil
+}
+
+// companions are the structured values a policy supplies alongside its decisions: the reasons of
+// each decision, from the <decision>_reasons rules, and the obligations, from the obligations rule.
+type companions struct {
+	Reasons     map[string]interface{} `json:"reasons,omitempty"`
+	Obligations interface{}            `json:"obligations,omitempty"`
+}
+
+// companionsOf returns the companion values of decisions in the policy document v, or nil when
+// the policy supplies none.
+func companionsOf(v interface{}, decisions []string) *companions {
+	document, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	c := &companions{Obligations: document[ObligationsRule]}
+
+	for _, d := range decisions {
+		reasons, ok := document[d+ReasonsSuffix]
+		if !ok {
+			continue
+		}
+		if c.Reasons == nil {
+			c.Reasons = map[string]interface{}{}
+		}
+		c.Reasons[d] = reasons
+	}
+
+	if c.Reasons == nil && c.Obligations == nil {
+		return nil
+	}
+
+	return c
+}
+
+// reasons returns the reasons as a proto struct, keyed by decision.
+func (c *companions) reasons() *structpb.Struct {
+	if c == nil || c.Reasons == nil {
+		return nil
+	}
+
+	reasons := &structpb.Struct{Fields: map[string]*structpb.Value{}}
+	for d, r := range c.Reasons {
+		reasons.Fields[d] = toValue(r)
+	}
+
+	return reasons
+}
+
+// obligations returns the obligations as a proto value.
+func (c *companions) obligations() *structpb.Value {
+	if c == nil || c.Obligations == nil {
+		return nil
+	}
+	return toValue(c.Obligations)
+}
@@ -0,0 +1,91 @@
+package impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const companionsPolicy = `package todo.GET.todos
+
+default allowed = false
+
+allowed {
+	input.resource.owner == input.user.id
+}
+
+allowed_reasons["not the owner of the todo"] {
+	not allowed
+}
+
+default visible = true
+
+obligations := {"mask": ["ssn"], "mfa": input.resource.sensitive}
+`
+
+func evalCompanions(t *testing.T, policy string, resource map[string]interface{}, decisions ...string) *isResult {
+	ctx := context.Background()
+	m := newTestManager(t, policy)
+
+	query, err := newQueryCache(10).prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+
+	input := map[string]interface{}{
+		InputUser:     map[string]interface{}{"id": "alice"},
+		InputResource: resource,
+	}
+
+	result, err := evalIs(ctx, query, testPath, input, decisions)
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestCompanions(t *testing.T) {
+	denied := evalCompanions(t, companionsPolicy, map[string]interface{}{"owner": "bob", "sensitive": true}, "allowed", "visible")
+	assert.False(t, denied.decisions[0].Is)
+	assert.True(t, denied.decisions[1].Is)
+	require.NotNil(t, denied.companions)
+	assert.Equal(t, map[string]interface{}{"allowed": []interface{}{"not the owner of the todo"}}, denied.companions.Reasons)
+	assert.Equal(t, map[string]interface{}{"mask": []interface{}{"ssn"}, "mfa": true}, denied.companions.Obligations)
+
+	assert.Equal(t, []interface{}{"not the owner of the todo"}, denied.companions.reasons().AsMap()["allowed"])
+	assert.Equal(t, true, denied.companions.obligations().GetStructValue().AsMap()["mfa"])
+
+	// the reasons of decisions that are not requested are not returned.
+	visible := evalCompanions(t, companionsPolicy, map[string]interface{}{"owner": "bob", "sensitive": false}, "visible")
+	require.NotNil(t, visible.companions)
+	assert.Nil(t, visible.companions.Reasons)
+
+	// an empty set of reasons is returned as such.
+	allowed := evalCompanions(t, companionsPolicy, map[string]interface{}{"owner": "alice"}, "allowed")
+	assert.True(t, allowed.decisions[0].Is)
+	assert.Equal(t, map[string]interface{}{"allowed": []interface{}{}}, allowed.companions.Reasons)
+}
+
+func TestCompanionsNone(t *testing.T) {
+	result := evalCompanions(t, testPolicy("alice"), map[string]interface{}{"owner": "alice"}, "allowed")
+	assert.Nil(t, result.companions)
+
+	assert.Nil(t, result.companions.reasons())
+	assert.Nil(t, result.companions.obligations())
+}
+
+func TestAnnotateCompanions(t *testing.T) {
+	d := &api.Decision{Annotations: map[string]string{}}
+	annotateCompanions(d, &companions{
+		Reasons:     map[string]interface{}{"allowed": []interface{}{"not the owner of the todo"}},
+		Obligations: map[string]interface{}{"mfa": true},
+	})
+
+	assert.JSONEq(t, `{"allowed": ["not the owner of the todo"]}`, d.Annotations[decisionlog.AnnotationDecisionReasons])
+	assert.JSONEq(t, `{"mfa": true}`, d.Annotations[decisionlog.AnnotationDecisionObligations])
+
+	d = &api.Decision{Annotations: map[string]string{}}
+	annotateCompanions(d, nil)
+	assert.Empty(t, d.Annotations)
+}
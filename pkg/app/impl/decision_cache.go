@@ -0,0 +1,347 @@
+package impl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/header"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	decisionlog_plugin "github.com/aserto-dev/topaz/decision_log/plugin"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/gobwas/glob"
+	"github.com/open-policy-agent/opa/plugins"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	decisionCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "topaz",
+		Subsystem: "decision_cache",
+		Name:      "hits_total",
+		Help:      "Number of Is calls answered from the decision cache.",
+	})
+	decisionCacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "topaz",
+		Subsystem: "decision_cache",
+		Name:      "misses_total",
+		Help:      "Number of Is calls evaluated because their decisions were not cached.",
+	})
+	decisionCacheFlushed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "topaz",
+		Subsystem: "decision_cache",
+		Name:      "flushed_total",
+		Help:      "Number of cached decisions removed by flush calls.",
+	})
+)
+
+// decisionCache holds the decisions of Is calls, per plugins manager of a runtime.
+//
+// Decisions are keyed by the identity and resolved user, the tenant, the policy path and
+// decisions, the canonical JSON encoding of the resource context and the bundle revision.
+// The decisions of a manager are dropped every time its compiler changes, which includes
+// every bundle activation, by a compiler trigger registered when the manager is created.
+// The decisions of a manager that was not registered are not cached.
+type decisionCache struct {
+	ttl        time.Duration
+	maxEntries int
+	paths      []decisionCachePath
+	now        func() time.Time
+
+	mu       sync.Mutex
+	managers map[*plugins.Manager]*cachedDecisions
+}
+
+type decisionCachePath struct {
+	path glob.Glob
+	ttl  time.Duration
+}
+
+// cachedDecisions are the decisions cached for a plugins manager. The generation
+// changes every time the decisions are dropped.
+type cachedDecisions struct {
+	revision   string
+	generation uint64
+	entries    map[string]*cachedDecision
+}
+
+type cachedDecision struct {
+	user     string
+	identity string
+	resource map[string]interface{}
+	isResult
+	expires time.Time
+}
+
+// decisionKey holds what the decisions of an Is call depend on.
+type decisionKey struct {
+	User         string                 `json:"user"`
+	IdentityType string                 `json:"identity_type"`
+	Identity     string                 `json:"identity"`
+	TenantID     string                 `json:"tenant_id"`
+	Path         string                 `json:"path"`
+	Decisions    []string               `json:"decisions"`
+	Resource     map[string]interface{} `json:"resource"`
+	Revision     string                 `json:"revision"`
+}
+
+// decisionTicket is handed out on a cache miss to cache the decisions of the call once evaluated.
+type decisionTicket struct {
+	cached     *cachedDecisions
+	generation uint64
+	key        string
+	entry      *cachedDecision
+}
+
+// newDecisionCache creates the decision cache, or returns nil when it is not enabled.
+func newDecisionCache(cfg *config.DecisionCacheConfig) (*decisionCache, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	c := &decisionCache{
+		ttl:        cfg.TTL,
+		maxEntries: cfg.MaxEntries,
+		now:        time.Now,
+		managers:   map[*plugins.Manager]*cachedDecisions{},
+	}
+
+	for i, p := range cfg.Paths {
+		g, err := glob.Compile(p.Path, '.')
+		if err != nil {
+			return nil, errors.Wrapf(err, "decision cache paths %d: invalid path [%s]", i, p.Path)
+		}
+		c.paths = append(c.paths, decisionCachePath{path: g, ttl: p.TTL})
+	}
+
+	return c, nil
+}
+
+// pathTTL returns the TTL of the decisions of a policy path.
+func (c *decisionCache) pathTTL(path string) time.Duration {
+	for _, p := range c.paths {
+		if p.path.Match(path) {
+			return p.ttl
+		}
+	}
+	return c.ttl
+}
+
+// lookup returns the cached decisions of an Is call. On a miss it returns a ticket to
+// cache the decisions of the call with, unless they are not to be cached.
+func (c *decisionCache) lookup(m *plugins.Manager, key *decisionKey) (*cachedDecision, *decisionTicket) {
+	if c == nil {
+		return nil, nil
+	}
+
+	ttl := c.pathTTL(key.Path)
+	if ttl <= 0 {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.managers[m]
+	if !ok {
+		return nil, nil
+	}
+
+	key.Revision = cached.revision
+	k := key.hash()
+	now := c.now()
+
+	if entry, ok := cached.entries[k]; ok {
+		if now.Before(entry.expires) {
+			decisionCacheHits.Inc()
+			return entry.copy(), nil
+		}
+		delete(cached.entries, k)
+	}
+
+	decisionCacheMisses.Inc()
+
+	return nil, &decisionTicket{
+		cached:     cached,
+		generation: cached.generation,
+		key:        k,
+		entry: &cachedDecision{
+			user:     key.User,
+			identity: key.Identity,
+			resource: key.Resource,
+			expires:  now.Add(ttl),
+		},
+	}
+}
+
+// store caches the decisions of the call of the ticket, unless the decisions of the
+// manager were dropped while they were evaluated.
+func (c *decisionCache) store(ticket *decisionTicket, result *isResult) {
+	if c == nil || ticket == nil || result == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached := ticket.cached
+	if cached.generation != ticket.generation {
+		return
+	}
+
+	if c.maxEntries > 0 && len(cached.entries) >= c.maxEntries {
+		c.evict(cached)
+	}
+
+	ticket.entry.isResult = *result
+	cached.entries[ticket.key] = ticket.entry.copy()
+}
+
+// evict removes the expired decisions, and the decision closest to expiry when none has expired.
+func (c *decisionCache) evict(cached *cachedDecisions) {
+	var (
+		now     = c.now()
+		next    string
+		expires time.Time
+	)
+
+	for k, entry := range cached.entries {
+		if !now.Before(entry.expires) {
+			delete(cached.entries, k)
+			continue
+		}
+		if next == "" || entry.expires.Before(expires) {
+			next, expires = k, entry.expires
+		}
+	}
+
+	if len(cached.entries) >= c.maxEntries && next != "" {
+		delete(cached.entries, next)
+	}
+}
+
+// flush removes the cached decisions of user, when set, whose resource context holds
+// the fields of resource, when set, and returns the number of decisions removed.
+func (c *decisionCache) flush(user string, resource map[string]interface{}) int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	flushed := 0
+	for _, cached := range c.managers {
+		for k, entry := range cached.entries {
+			if user != "" && user != entry.user && user != entry.identity {
+				continue
+			}
+			if !containsFields(entry.resource, resource) {
+				continue
+			}
+
+			delete(cached.entries, k)
+			flushed++
+		}
+	}
+
+	decisionCacheFlushed.Add(float64(flushed))
+
+	return flushed
+}
+
+// register registers the compiler trigger that drops the decisions cached for m, and
+// updates their bundle revision, when a bundle activates.
+func (c *decisionCache) register(m *plugins.Manager) {
+	if c == nil {
+		return
+	}
+
+	cached := &cachedDecisions{entries: map[string]*cachedDecision{}}
+
+	c.mu.Lock()
+	c.managers[m] = cached
+	c.mu.Unlock()
+
+	m.RegisterCompilerTrigger(func(txn storage.Transaction) {
+		revision, _ := decisionlog_plugin.ReadRevision(context.Background(), m.Store, txn)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		cached.revision = revision
+		cached.generation++
+		cached.entries = map[string]*cachedDecision{}
+	})
+}
+
+func (k *decisionKey) hash() string {
+	// maps are encoded with sorted keys, which makes the encoding of the resource canonical.
+	b, _ := json.Marshal(k)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// copy returns a copy of the decisions of the entry, which callers may modify. The companion
+// values are shared, they are not modified.
+func (e *cachedDecision) copy() *cachedDecision {
+	c := *e
+
+	c.decisions = make([]*authorizer.Decision, len(e.decisions))
+	for i, d := range e.decisions {
+		c.decisions[i] = &authorizer.Decision{Decision: d.Decision, Is: d.Is}
+	}
+
+	c.outcomes = make(map[string]bool, len(e.outcomes))
+	for k, v := range e.outcomes {
+		c.outcomes[k] = v
+	}
+
+	return &c
+}
+
+// containsFields reports whether resource holds every field of fields with the same value.
+func containsFields(resource, fields map[string]interface{}) bool {
+	for k, v := range fields {
+		if rv, ok := resource[k]; !ok || !reflect.DeepEqual(rv, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// newDecisionKey returns the cache key of an Is call from its request and evaluation input.
+func newDecisionKey(ctx context.Context, req *authorizer.IsRequest, input map[string]interface{}) *decisionKey {
+	return &decisionKey{
+		User:         getID(input),
+		IdentityType: req.IdentityContext.Type.String(),
+		Identity:     req.IdentityContext.Identity,
+		TenantID:     header.ExtractTenantID(ctx),
+		Path:         req.PolicyContext.Path,
+		Decisions:    req.PolicyContext.Decisions,
+		Resource:     req.ResourceContext.AsMap(),
+	}
+}
+
+// FlushDecisionCache removes cached Is decisions, those of a user, those whose resource context
+// holds the given fields, or all of them.
+func (s *AuthorizerServer) FlushDecisionCache(ctx context.Context, req *topazauthz.FlushDecisionCacheRequest) (*topazauthz.FlushDecisionCacheResponse, error) {
+	var resource map[string]interface{}
+	if req.ResourceContext != nil {
+		resource = req.ResourceContext.AsMap()
+	}
+
+	flushed := s.decisions.flush(req.User, resource)
+
+	s.logger.Debug().Str("user", req.User).Interface("resource_context", resource).Int("flushed", flushed).Msg("flushed decision cache")
+
+	return &topazauthz.FlushDecisionCacheResponse{Flushed: int32(flushed)}, nil
+}
@@ -0,0 +1,234 @@
+package impl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/open-policy-agent/opa/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestDecisionCache(t *testing.T, m *plugins.Manager, cfg *config.DecisionCacheConfig) (*decisionCache, *testClock) {
+	cfg.Enabled = true
+
+	c, err := newDecisionCache(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+
+	c.register(m)
+
+	clock := &testClock{now: time.Now()}
+	c.now = clock.Now
+
+	return c, clock
+}
+
+func testKey(user, owner string) *decisionKey {
+	return &decisionKey{
+		User:      user,
+		Identity:  user + "@acmecorp.com",
+		Path:      testPath,
+		Decisions: []string{"allowed"},
+		Resource:  map[string]interface{}{"owner": owner, "kind": "todo"},
+	}
+}
+
+// cacheDecision looks the key up and caches an allowed decision on a miss, it reports whether the key was cached.
+func cacheDecision(c *decisionCache, m *plugins.Manager, key *decisionKey) bool {
+	cached, ticket := c.lookup(m, key)
+	if cached != nil {
+		return true
+	}
+
+	c.store(ticket, &isResult{
+		decisions: []*authorizer.Decision{{Decision: "allowed", Is: true}},
+		outcomes:  map[string]bool{"allowed": true},
+	})
+	return false
+}
+
+func TestDecisionCache(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	c, clock := newTestDecisionCache(t, m, &config.DecisionCacheConfig{TTL: time.Minute})
+
+	assert.False(t, cacheDecision(c, m, testKey("alice", "alice")))
+	assert.True(t, cacheDecision(c, m, testKey("alice", "alice")))
+	assert.False(t, cacheDecision(c, m, testKey("alice", "bob")))
+	assert.False(t, cacheDecision(c, m, testKey("bob", "alice")))
+
+	cached, _ := c.lookup(m, testKey("alice", "alice"))
+	require.NotNil(t, cached)
+	assert.Equal(t, []*authorizer.Decision{{Decision: "allowed", Is: true}}, cached.decisions)
+
+	// cached decisions are copies.
+	cached.decisions[0].Is = false
+	cached, _ = c.lookup(m, testKey("alice", "alice"))
+	assert.True(t, cached.decisions[0].Is)
+
+	clock.now = clock.now.Add(time.Minute)
+	assert.False(t, cacheDecision(c, m, testKey("alice", "alice")))
+}
+
+func TestDecisionCacheCompanions(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	c, _ := newTestDecisionCache(t, m, &config.DecisionCacheConfig{TTL: time.Minute})
+
+	key := testKey("alice", "bob")
+	_, ticket := c.lookup(m, key)
+	c.store(ticket, &isResult{
+		decisions:  []*authorizer.Decision{{Decision: "allowed"}},
+		companions: &companions{Reasons: map[string]interface{}{"allowed": []interface{}{"not the owner"}}},
+	})
+
+	cached, _ := c.lookup(m, key)
+	require.NotNil(t, cached)
+	assert.Equal(t, map[string]interface{}{"allowed": []interface{}{"not the owner"}}, cached.companions.Reasons)
+}
+
+func TestDecisionCachePathTTL(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	c, clock := newTestDecisionCache(t, m, &config.DecisionCacheConfig{
+		TTL: time.Minute,
+		Paths: []config.DecisionCachePath{
+			{Path: "todo.DELETE.**", TTL: 0},
+			{Path: "todo.*.todos", TTL: time.Second},
+		},
+	})
+
+	key := testKey("alice", "alice")
+	assert.False(t, cacheDecision(c, m, key))
+	assert.True(t, cacheDecision(c, m, key))
+
+	clock.now = clock.now.Add(time.Second)
+	assert.False(t, cacheDecision(c, m, key))
+
+	key.Path = "todo.DELETE.todos.__id"
+	cached, ticket := c.lookup(m, key)
+	assert.Nil(t, cached)
+	assert.Nil(t, ticket)
+}
+
+func TestDecisionCacheBundleChange(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	c, _ := newTestDecisionCache(t, m, &config.DecisionCacheConfig{TTL: time.Minute})
+
+	assert.False(t, cacheDecision(c, m, testKey("alice", "alice")))
+
+	// a decision evaluated while the bundle changes is not cached.
+	_, ticket := c.lookup(m, testKey("bob", "alice"))
+	require.NotNil(t, ticket)
+
+	upsertPolicy(t, m, "policy.rego", testPolicy("bob"))
+
+	c.store(ticket, &isResult{decisions: []*authorizer.Decision{{Decision: "allowed", Is: true}}})
+
+	assert.False(t, cacheDecision(c, m, testKey("alice", "alice")))
+	assert.False(t, cacheDecision(c, m, testKey("bob", "alice")))
+}
+
+func TestDecisionCacheMaxEntries(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	c, _ := newTestDecisionCache(t, m, &config.DecisionCacheConfig{TTL: time.Minute, MaxEntries: 2})
+
+	for _, owner := range []string{"alice", "bob", "carol"} {
+		cacheDecision(c, m, testKey("alice", owner))
+	}
+
+	assert.Len(t, c.managers[m].entries, 2)
+}
+
+func TestDecisionCacheEvictsClosestToExpiry(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	c, clock := newTestDecisionCache(t, m, &config.DecisionCacheConfig{TTL: time.Minute, MaxEntries: 3})
+
+	for _, owner := range []string{"alice", "bob", "carol"} {
+		cacheDecision(c, m, testKey("alice", owner))
+		clock.now = clock.now.Add(time.Second)
+	}
+
+	// the first decision cached expires first.
+	cacheDecision(c, m, testKey("alice", "dave"))
+
+	assert.Len(t, c.managers[m].entries, 3)
+	assert.False(t, cacheDecision(c, m, testKey("alice", "alice")))
+}
+
+func TestDecisionCacheInvalidPath(t *testing.T) {
+	_, err := newDecisionCache(&config.DecisionCacheConfig{
+		Enabled: true,
+		TTL:     time.Minute,
+		Paths:   []config.DecisionCachePath{{Path: "todo.[", TTL: time.Second}},
+	})
+	assert.Error(t, err)
+}
+
+func TestDecisionCacheFlush(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+
+	fill := func(c *decisionCache) {
+		for _, user := range []string{"alice", "bob"} {
+			for _, owner := range []string{"alice", "bob"} {
+				cacheDecision(c, m, testKey(user, owner))
+			}
+		}
+	}
+
+	tests := map[string]struct {
+		user     string
+		resource map[string]interface{}
+		flushed  int
+	}{
+		"all":               {"", nil, 4},
+		"user id":           {"alice", nil, 2},
+		"identity":          {"bob@acmecorp.com", nil, 2},
+		"unknown user":      {"carol", nil, 0},
+		"object":            {"", map[string]interface{}{"kind": "todo", "owner": "bob"}, 2},
+		"unknown object":    {"", map[string]interface{}{"kind": "list", "owner": "bob"}, 0},
+		"user and object":   {"alice", map[string]interface{}{"owner": "bob"}, 1},
+		"object all fields": {"", map[string]interface{}{"kind": "todo"}, 4},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newTestDecisionCache(t, m, &config.DecisionCacheConfig{TTL: time.Minute})
+			fill(c)
+
+			assert.Equal(t, tc.flushed, c.flush(tc.user, tc.resource))
+			assert.Len(t, c.managers[m].entries, 4-tc.flushed)
+		})
+	}
+}
+
+func TestDecisionCacheUnregistered(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	c, _ := newTestDecisionCache(t, newTestManager(t, testPolicy("alice")), &config.DecisionCacheConfig{TTL: time.Minute})
+
+	// the decisions of a manager whose compiler trigger is not registered are not cached.
+	cached, ticket := c.lookup(m, testKey("alice", "alice"))
+	assert.Nil(t, cached)
+	assert.Nil(t, ticket)
+}
+
+func TestDecisionCacheDisabled(t *testing.T) {
+	c, err := newDecisionCache(&config.DecisionCacheConfig{TTL: time.Minute})
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	cached, ticket := c.lookup(nil, testKey("alice", "alice"))
+	assert.Nil(t, cached)
+	assert.Nil(t, ticket)
+
+	c.store(ticket, nil)
+	assert.Equal(t, 0, c.flush("", nil))
+}
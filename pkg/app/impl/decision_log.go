@@ -0,0 +1,124 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	runtime "github.com/aserto-dev/runtime"
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	decisionlog_plugin "github.com/aserto-dev/topaz/decision_log/plugin"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// decisionLogger returns the decision log plugin of the runtime when decisions made
+// by the given authorizer call are logged, nil otherwise.
+func decisionLogger(rt *runtime.Runtime, apiName string) *decisionlog_plugin.DecisionLogsPlugin {
+	dlPlugin := decisionlog_plugin.Lookup(rt.GetPluginsManager())
+	if dlPlugin == nil || !dlPlugin.Enabled(apiName) {
+		return nil
+	}
+	return dlPlugin
+}
+
+// recordDSCalls reports whether the ds builtin calls of decisions made by the given
+// authorizer call are recorded.
+func recordDSCalls(rt *runtime.Runtime, apiName string) bool {
+	dlPlugin := decisionLogger(rt, apiName)
+	return dlPlugin != nil && dlPlugin.RecordDSCalls()
+}
+
+// newDecision creates a decision record for an authorizer call from its evaluation input.
+func newDecision(
+	ctx context.Context,
+	apiName string,
+	path string,
+	policyContext *api.PolicyContext,
+	policyInstance *api.PolicyInstance,
+	identityContext *api.IdentityContext,
+	resourceContext *structpb.Struct,
+	input map[string]interface{},
+	outcomes map[string]bool,
+) *api.Decision {
+	return &api.Decision{
+		Id:        uuid.NewString(),
+		Timestamp: timestamppb.New(time.Now().In(time.UTC)),
+		Path:      path,
+		Policy: &api.DecisionPolicy{
+			Context:        policyContext,
+			PolicyInstance: policyInstance,
+		},
+		User: &api.DecisionUser{
+			Context: identityContext,
+			Id:      getID(input),
+			Email:   getEmail(input),
+		},
+		TenantId: getTenantID(ctx),
+		Resource: resourceContext,
+		Outcomes: outcomes,
+		Annotations: map[string]string{
+			decisionlog.AnnotationAPI: apiName,
+		},
+	}
+}
+
+// annotateResult stores the JSON encoded result of a query or compile call on the decision.
+func annotateResult(d *api.Decision, query string, result interface{}) {
+	d.Annotations[decisionlog.AnnotationQuery] = query
+
+	if b, err := json.Marshal(result); err == nil {
+		d.Annotations[decisionlog.AnnotationResult] = string(b)
+	}
+}
+
+// annotateEval stores the evaluation time and the directory calls made by ds builtins on the decision.
+func annotateEval(d *api.Decision, elapsed time.Duration, calls *ds.Calls) {
+	d.Annotations[decisionlog.AnnotationEvalDuration] = strconv.FormatInt(elapsed.Nanoseconds(), 10)
+	d.Annotations[decisionlog.AnnotationEvalDSCalls] = strconv.FormatInt(calls.Count(), 10)
+
+	if records := calls.Records(); len(records) > 0 {
+		if b, err := json.Marshal(records); err == nil {
+			d.Annotations[decisionlog.AnnotationEvalDSTrace] = string(b)
+		}
+	}
+}
+
+// annotateCached marks a decision answered from the decision cache, which was not evaluated.
+func annotateCached(d *api.Decision) {
+	d.Annotations[decisionlog.AnnotationEvalCached] = "true"
+}
+
+// annotateErrors stores the JSON encoded errors of the packages of a decision tree that failed to evaluate.
+func annotateErrors(d *api.Decision, errs map[string]string) {
+	if len(errs) == 0 {
+		return
+	}
+
+	if b, err := json.Marshal(errs); err == nil {
+		d.Annotations[decisionlog.AnnotationEvalErrors] = string(b)
+	}
+}
+
+// annotateCompanions stores the JSON encoded reasons and obligations the policy supplied alongside the decisions.
+func annotateCompanions(d *api.Decision, c *companions) {
+	if c == nil {
+		return
+	}
+
+	if c.Reasons != nil {
+		if b, err := json.Marshal(c.Reasons); err == nil {
+			d.Annotations[decisionlog.AnnotationDecisionReasons] = string(b)
+		}
+	}
+
+	if c.Obligations != nil {
+		if b, err := json.Marshal(c.Obligations); err == nil {
+			d.Annotations[decisionlog.AnnotationDecisionObligations] = string(b)
+		}
+	}
+}
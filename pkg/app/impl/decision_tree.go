@@ -0,0 +1,248 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	runtime "github.com/aserto-dev/runtime"
+	decisionlog_plugin "github.com/aserto-dev/topaz/decision_log/plugin"
+	"github.com/open-policy-agent/opa/plugins"
+	"github.com/open-policy-agent/opa/rego"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// DecisionTreeErrorsHeader is the response header of a DecisionTree call that lists the packages
+// that failed to evaluate, as a JSON object of package name to error message. The gateway returns
+// it as the Grpc-Metadata-Aserto-Decision-Tree-Errors HTTP header.
+const DecisionTreeErrorsHeader = "aserto-decision-tree-errors"
+
+const (
+	// DecisionTreeValuesHeader is the request header of a DecisionTree call that overrides whether
+	// values that are not booleans are returned, as true or false.
+	DecisionTreeValuesHeader = "aserto-decision-tree-values"
+	// DecisionTreeMaxDepthHeader is the request header of a DecisionTree call that overrides the
+	// depth to which rule objects are recursed into.
+	DecisionTreeMaxDepthHeader = "aserto-decision-tree-max-depth"
+)
+
+// treeOptions select the values of the packages of a decision tree.
+type treeOptions struct {
+	filter        func(string) bool
+	includeValues bool
+	maxDepth      int
+
+	// packages holds the names of the packages of the tree and of their parents, whose documents
+	// are nested in the documents of their parent packages.
+	packages map[string]struct{}
+}
+
+// treeOptions returns the options of a decision tree call from the configuration and the request headers.
+func (s *AuthorizerServer) treeOptions(ctx context.Context, decisions []string, policyList []runtime.Policy) (*treeOptions, error) {
+	filter, err := initDecisionFilter(decisions)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &treeOptions{
+		filter:        filter,
+		includeValues: s.cfg.Authorizer.DecisionTree.IncludeValues,
+		maxDepth:      s.cfg.Authorizer.DecisionTree.MaxDepth,
+		packages:      map[string]struct{}{},
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if v := md.Get(DecisionTreeValuesHeader); len(v) > 0 {
+		if opts.includeValues, err = strconv.ParseBool(v[0]); err != nil {
+			return nil, aerr.ErrInvalidArgument.Msgf("invalid %s header [%s]", DecisionTreeValuesHeader, v[0])
+		}
+	}
+
+	if v := md.Get(DecisionTreeMaxDepthHeader); len(v) > 0 {
+		if opts.maxDepth, err = strconv.Atoi(v[0]); err != nil || opts.maxDepth < 0 {
+			return nil, aerr.ErrInvalidArgument.Msgf("invalid %s header [%s]", DecisionTreeMaxDepthHeader, v[0])
+		}
+	}
+
+	for _, policy := range policyList {
+		segments := strings.Split(policy.PackageName, ".")
+		for i := range segments {
+			opts.packages[strings.Join(segments[:i+1], ".")] = struct{}{}
+		}
+	}
+
+	return opts, nil
+}
+
+// packageResult is the outcome of the evaluation of a package of a decision tree.
+type packageResult struct {
+	decisions map[string]interface{}
+	outcomes  map[string]bool
+	err       error
+}
+
+// evalDecisionTree evaluates the packages of policyList concurrently, up to the configured
+// limit, and returns their results in the order of policyList.
+func (s *AuthorizerServer) evalDecisionTree(
+	ctx context.Context,
+	m *plugins.Manager,
+	policyList []runtime.Policy,
+	policyContext *api.PolicyContext,
+	input map[string]interface{},
+	opts *treeOptions,
+) []*packageResult {
+	results := make([]*packageResult, len(policyList))
+
+	g := &errgroup.Group{}
+	if maxConcurrency := s.cfg.Authorizer.DecisionTree.MaxConcurrency; maxConcurrency > 0 {
+		g.SetLimit(maxConcurrency)
+	}
+
+	for i, policy := range policyList {
+		i, packageName := i, policy.PackageName
+		g.Go(func() error {
+			results[i] = s.evalPackage(ctx, m, packageName, policyContext, input, opts)
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return results
+}
+
+// evalPackage evaluates the decisions of a package selected by opts.
+func (s *AuthorizerServer) evalPackage(
+	ctx context.Context,
+	m *plugins.Manager,
+	packageName string,
+	policyContext *api.PolicyContext,
+	input map[string]interface{},
+	opts *treeOptions,
+) *packageResult {
+	if err := ctx.Err(); err != nil {
+		return &packageResult{err: err}
+	}
+
+	queryStmt := "x = data." + packageName
+
+	packageContext := proto.Clone(policyContext).(*api.PolicyContext)
+	packageContext.Path = packageName
+
+	packageInput := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		packageInput[k] = v
+	}
+	packageInput[InputPolicy] = packageContext
+
+	qry, err := s.queries.prepare(ctx, m, queryStmt)
+	if err != nil {
+		return &packageResult{err: aerr.ErrBadQuery.Err(err).Str("query", queryStmt)}
+	}
+
+	limitCtx, limit, cancel := s.limits.limit(ctx, decisionlog_plugin.APIDecisionTree, packageName)
+	queryResults, err := qry.Eval(limitCtx, rego.EvalInput(packageInput))
+	cancel()
+
+	if err = limit.err(err); exceeded(err) {
+		return &packageResult{err: err}
+	} else if err != nil {
+		return &packageResult{err: aerr.ErrBadQuery.Err(err).Str("query", queryStmt).Msg("query evaluation failed")}
+	} else if len(queryResults) == 0 {
+		return &packageResult{err: aerr.ErrBadQuery.Err(err).Str("query", queryStmt).Msg("undefined results")}
+	}
+
+	result := &packageResult{
+		decisions: map[string]interface{}{},
+		outcomes:  map[string]bool{},
+	}
+
+	if values, ok := queryResults[0].Bindings["x"].(map[string]interface{}); ok {
+		result.decisions = opts.walk(packageName, "", values, 0, false, result.outcomes)
+	}
+
+	return result
+}
+
+// walk returns the decisions of the document of a package, or of a rule object nested at depth in it,
+// and records the outcome of its boolean decisions. Decisions are named by their path in the package,
+// and selected is set when the name of the enclosing object passes the filter.
+func (o *treeOptions) walk(
+	packageName, prefix string,
+	values map[string]interface{},
+	depth int,
+	selected bool,
+	outcomes map[string]bool,
+) map[string]interface{} {
+	decisions := map[string]interface{}{}
+
+	for k, v := range values {
+		name := k
+		if prefix != "" {
+			name = prefix + "." + k
+		}
+
+		// the documents of sub-packages are evaluated as packages of their own.
+		if _, ok := o.packages[packageName+"."+name]; ok {
+			continue
+		}
+
+		match := selected || o.filter(name)
+
+		switch x := v.(type) {
+		case bool:
+			if match {
+				decisions[k] = x
+				outcomes[packageName+"."+name] = x
+			}
+		case map[string]interface{}:
+			if depth < o.maxDepth {
+				if nested := o.walk(packageName, name, x, depth+1, match, outcomes); len(nested) > 0 {
+					decisions[k] = nested
+				}
+			} else if match && o.includeValues {
+				decisions[k] = x
+			}
+		default:
+			if match && o.includeValues {
+				decisions[k] = x
+			}
+		}
+	}
+
+	return decisions
+}
+
+// toStruct converts the decisions of a decision tree, whose numbers are json.Number, into a proto struct.
+func toStruct(v map[string]interface{}) (*structpb.Struct, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &structpb.Struct{}
+	if err := protojson.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// setDecisionTreeErrors returns the errors of the packages that failed to evaluate in the response header.
+func setDecisionTreeErrors(ctx context.Context, errs map[string]string) error {
+	b, err := json.Marshal(errs)
+	if err != nil {
+		return err
+	}
+
+	return grpc.SetHeader(ctx, metadata.Pairs(DecisionTreeErrorsHeader, string(b)))
+}
@@ -0,0 +1,235 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	runtime "github.com/aserto-dev/runtime"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+var treePolicies = map[string]string{
+	"a.rego": `package tree.a
+
+allowed = true
+visible = false
+`,
+	"b.rego": `package tree.b
+
+default allowed = false
+
+allowed {
+	input.resource.owner == "alice"
+}
+`,
+	"broken.rego": `package tree.broken
+
+allowed = true {
+	input.resource.owner
+}
+
+allowed = false {
+	input.resource.owner
+}
+`,
+}
+
+const valuesPolicy = `package tree.values
+
+allowed = true
+plan = "pro"
+quota = 10
+
+features := {
+	"beta": true,
+	"theme": "dark",
+	"limits": {"projects": 3, "export": false},
+}
+`
+
+const subPackagePolicy = `package tree.values.sub
+
+allowed = false
+`
+
+func newTreeServer(maxConcurrency int) *AuthorizerServer {
+	cfg := &config.Common{}
+	cfg.Authorizer.DecisionTree.MaxConcurrency = maxConcurrency
+
+	return &AuthorizerServer{cfg: cfg, queries: newQueryCache(10)}
+}
+
+func TestEvalDecisionTree(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	for id, policy := range treePolicies {
+		upsertPolicy(t, m, id, policy)
+	}
+
+	policyList := []runtime.Policy{
+		{PackageName: "tree.a"},
+		{PackageName: "tree.broken"},
+		{PackageName: "tree.b"},
+	}
+	policyContext := &api.PolicyContext{Decisions: []string{"allowed"}}
+	input := map[string]interface{}{InputResource: map[string]interface{}{"owner": "alice"}}
+
+	for _, maxConcurrency := range []int{0, 1, 2} {
+		s := newTreeServer(maxConcurrency)
+
+		opts, err := s.treeOptions(context.Background(), policyContext.Decisions, policyList)
+		require.NoError(t, err)
+
+		results := s.evalDecisionTree(context.Background(), m, policyList, policyContext, input, opts)
+		require.Len(t, results, 3)
+
+		assert.NoError(t, results[0].err)
+		assert.Equal(t, map[string]interface{}{"allowed": true}, results[0].decisions)
+		assert.Equal(t, map[string]bool{"tree.a.allowed": true}, results[0].outcomes)
+
+		assert.Error(t, results[1].err)
+
+		assert.NoError(t, results[2].err)
+		assert.Equal(t, map[string]interface{}{"allowed": true}, results[2].decisions)
+	}
+}
+
+func TestEvalDecisionTreeCanceled(t *testing.T) {
+	m := newTestManager(t, treePolicies["a.rego"])
+	s := newTreeServer(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	opts, err := s.treeOptions(ctx, []string{"*"}, nil)
+	require.NoError(t, err)
+
+	results := s.evalDecisionTree(ctx, m, []runtime.Policy{{PackageName: "tree.a"}}, &api.PolicyContext{}, nil, opts)
+	require.Len(t, results, 1)
+	assert.ErrorIs(t, results[0].err, context.Canceled)
+}
+
+func TestEvalDecisionTreeValues(t *testing.T) {
+	m := newTestManager(t, valuesPolicy)
+	upsertPolicy(t, m, "sub.rego", subPackagePolicy)
+
+	policyList := []runtime.Policy{{PackageName: "tree.values"}, {PackageName: "tree.values.sub"}}
+
+	tests := map[string]struct {
+		decisions     []string
+		includeValues bool
+		maxDepth      int
+		expected      map[string]interface{}
+		outcomes      map[string]bool
+	}{
+		"booleans": {
+			decisions: []string{"*"},
+			expected:  map[string]interface{}{"allowed": true},
+			outcomes:  map[string]bool{"tree.values.allowed": true},
+		},
+		"values": {
+			decisions:     []string{"*"},
+			includeValues: true,
+			expected: map[string]interface{}{
+				"allowed":  true,
+				"plan":     "pro",
+				"quota":    json.Number("10"),
+				"features": map[string]interface{}{"beta": true, "theme": "dark", "limits": map[string]interface{}{"projects": json.Number("3"), "export": false}},
+			},
+			outcomes: map[string]bool{"tree.values.allowed": true},
+		},
+		"nested booleans": {
+			decisions: []string{"*"},
+			maxDepth:  2,
+			expected: map[string]interface{}{
+				"allowed":  true,
+				"features": map[string]interface{}{"beta": true, "limits": map[string]interface{}{"export": false}},
+			},
+			outcomes: map[string]bool{"tree.values.allowed": true, "tree.values.features.beta": true, "tree.values.features.limits.export": false},
+		},
+		"max depth": {
+			decisions:     []string{"features"},
+			includeValues: true,
+			maxDepth:      1,
+			expected: map[string]interface{}{
+				"features": map[string]interface{}{"beta": true, "theme": "dark", "limits": map[string]interface{}{"projects": json.Number("3"), "export": false}},
+			},
+			outcomes: map[string]bool{"tree.values.features.beta": true},
+		},
+		"glob": {
+			decisions:     []string{"features.*", "pl*"},
+			includeValues: true,
+			maxDepth:      1,
+			expected: map[string]interface{}{
+				"plan":     "pro",
+				"features": map[string]interface{}{"beta": true, "theme": "dark", "limits": map[string]interface{}{"projects": json.Number("3"), "export": false}},
+			},
+			outcomes: map[string]bool{"tree.values.features.beta": true},
+		},
+		"super glob": {
+			decisions: []string{"features.**"},
+			maxDepth:  2,
+			expected: map[string]interface{}{
+				"features": map[string]interface{}{"beta": true, "limits": map[string]interface{}{"export": false}},
+			},
+			outcomes: map[string]bool{"tree.values.features.beta": true, "tree.values.features.limits.export": false},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newTreeServer(0)
+			s.cfg.Authorizer.DecisionTree.IncludeValues = tc.includeValues
+			s.cfg.Authorizer.DecisionTree.MaxDepth = tc.maxDepth
+
+			opts, err := s.treeOptions(context.Background(), tc.decisions, policyList)
+			require.NoError(t, err)
+
+			results := s.evalDecisionTree(context.Background(), m, policyList[:1], &api.PolicyContext{}, nil, opts)
+			require.NoError(t, results[0].err)
+
+			// the document of the sub-package is not returned as a value of its parent.
+			assert.Equal(t, tc.expected, results[0].decisions)
+			assert.Equal(t, tc.outcomes, results[0].outcomes)
+
+			paths, err := toStruct(map[string]interface{}{"tree.values": results[0].decisions})
+			require.NoError(t, err)
+			assert.NotNil(t, paths.Fields["tree.values"])
+		})
+	}
+}
+
+func TestTreeOptionsHeaders(t *testing.T) {
+	s := newTreeServer(0)
+	s.cfg.Authorizer.DecisionTree.MaxDepth = 1
+
+	opts, err := s.treeOptions(context.Background(), []string{"*"}, nil)
+	require.NoError(t, err)
+	assert.False(t, opts.includeValues)
+	assert.Equal(t, 1, opts.maxDepth)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		DecisionTreeValuesHeader, "true",
+		DecisionTreeMaxDepthHeader, "3",
+	))
+
+	opts, err = s.treeOptions(ctx, []string{"*"}, nil)
+	require.NoError(t, err)
+	assert.True(t, opts.includeValues)
+	assert.Equal(t, 3, opts.maxDepth)
+
+	for _, md := range []metadata.MD{
+		metadata.Pairs(DecisionTreeValuesHeader, "yes please"),
+		metadata.Pairs(DecisionTreeMaxDepthHeader, "-1"),
+	} {
+		_, err := s.treeOptions(metadata.NewIncomingContext(context.Background(), md), []string{"*"}, nil)
+		assert.Error(t, err)
+	}
+
+	_, err = s.treeOptions(context.Background(), []string{"features.[a"}, nil)
+	assert.Error(t, err)
+}
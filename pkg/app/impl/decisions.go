@@ -0,0 +1,84 @@
+package impl
+
+import (
+	"context"
+
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	decisionlog_plugin "github.com/aserto-dev/topaz/decision_log/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SearchDecisions returns the logged decisions matching the request, in the order of the request.
+// Decisions are read back from the decision logger of the policy runtime, which must support it.
+func (s *AuthorizerServer) SearchDecisions(ctx context.Context, req *topazauthz.SearchDecisionsRequest) (*topazauthz.SearchDecisionsResponse, error) {
+	resp := &topazauthz.SearchDecisionsResponse{}
+
+	q, err := decisionsQuery(req)
+	if err != nil {
+		return resp, err
+	}
+
+	rt, err := s.getRuntime(ctx, req.PolicyInstance)
+	if err != nil {
+		return resp, err
+	}
+
+	dlPlugin := decisionlog_plugin.Lookup(rt.GetPluginsManager())
+	if dlPlugin == nil {
+		return resp, status.Error(codes.Unimplemented, "decision logging is not configured")
+	}
+
+	reader, ok := dlPlugin.Reader()
+	if !ok {
+		return resp, status.Error(codes.Unimplemented, decisionlog.ErrNotReadable.Error())
+	}
+
+	decisions, err := reader.Search(ctx, q)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to search decisions")
+		return resp, err
+	}
+
+	resp.Decisions = decisions
+	if n := len(decisions); n > 0 {
+		resp.NextPageToken = decisionlog.PositionOf(decisions[n-1]).Token()
+	}
+
+	return resp, nil
+}
+
+// decisionsQuery returns the validated decision log query of a search request.
+func decisionsQuery(req *topazauthz.SearchDecisionsRequest) (*decisionlog.Query, error) {
+	q := &decisionlog.Query{
+		User:        req.User,
+		Path:        req.Path,
+		Outcome:     req.Outcome,
+		TenantID:    req.TenantId,
+		Limit:       int(req.Limit),
+		OldestFirst: req.OldestFirst,
+	}
+
+	if req.From != nil {
+		q.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		q.To = req.To.AsTime()
+	}
+
+	if req.PageToken != "" {
+		after, err := decisionlog.ParsePosition(req.PageToken)
+		if err != nil {
+			return nil, aerr.ErrInvalidArgument.Msg(err.Error())
+		}
+		q.After = after
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, aerr.ErrInvalidArgument.Msg(err.Error())
+	}
+
+	return q, nil
+}
@@ -0,0 +1,163 @@
+package impl
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/gobwas/glob"
+	"github.com/open-policy-agent/opa/topdown"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+)
+
+// Errors raised by topaz itself use the E5xxxx range, the E1xxxx to E3xxxx ranges belong to the
+// aserto libraries whose errors share the same registry.
+var (
+	// ErrEvalTimeout is returned when a policy evaluation exceeds its time budget.
+	ErrEvalTimeout = cerr.NewAsertoError("E50001", codes.DeadlineExceeded, http.StatusGatewayTimeout, "evaluation time budget exceeded")
+	// ErrDSCallLimit is returned when a policy evaluation makes more directory calls than it is allowed.
+	ErrDSCallLimit = cerr.NewAsertoError("E50002", codes.ResourceExhausted, http.StatusTooManyRequests, "directory call limit exceeded")
+
+	evalLimitsExceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "topaz",
+		Subsystem: "eval",
+		Name:      "limits_exceeded_total",
+		Help:      "Number of policy evaluations stopped by their time budget or directory call limit.",
+	}, []string{"api", "limit"})
+)
+
+const (
+	limitTimeout = "timeout"
+	limitDSCalls = "ds_calls"
+)
+
+// evalLimits hold the time budgets and directory call limits of the policy evaluations of the
+// authorizer calls. Each evaluation is bounded on its own: an Is call, an item of a batch, a
+// package of a decision tree or a query.
+type evalLimits struct {
+	timeout    time.Duration
+	apis       map[string]time.Duration
+	maxDSCalls int
+	paths      []evalLimitsPath
+}
+
+type evalLimitsPath struct {
+	path       glob.Glob
+	timeout    time.Duration
+	maxDSCalls int
+}
+
+// evalLimit is the bound of a single evaluation.
+type evalLimit struct {
+	api        string
+	path       string
+	timeout    time.Duration
+	deadline   time.Time
+	maxDSCalls int
+	dsCalls    *ds.CallLimit
+}
+
+func newEvalLimits(cfg *config.EvalLimitsConfig) (*evalLimits, error) {
+	l := &evalLimits{
+		timeout:    cfg.Timeout,
+		apis:       cfg.APIs,
+		maxDSCalls: cfg.MaxDSCalls,
+	}
+
+	for i, p := range cfg.Paths {
+		g, err := glob.Compile(p.Path, '.')
+		if err != nil {
+			return nil, errors.Wrapf(err, "eval limits paths %d: invalid path [%s]", i, p.Path)
+		}
+		l.paths = append(l.paths, evalLimitsPath{path: g, timeout: p.Timeout, maxDSCalls: p.MaxDSCalls})
+	}
+
+	return l, nil
+}
+
+// limit bounds an evaluation of the policy path made by an authorizer call. The returned
+// context carries the time budget and directory call limit of the evaluation, and the
+// returned function releases it once the evaluation is done.
+func (l *evalLimits) limit(ctx context.Context, apiName, path string) (context.Context, *evalLimit, context.CancelFunc) {
+	el := &evalLimit{api: apiName, path: path}
+	if l == nil {
+		return ctx, el, func() {}
+	}
+
+	el.timeout = l.timeout
+	if timeout, ok := l.apis[apiName]; ok {
+		el.timeout = timeout
+	}
+	el.maxDSCalls = l.maxDSCalls
+
+	for _, p := range l.paths {
+		if !p.path.Match(path) {
+			continue
+		}
+		el.timeout = tighter(el.timeout, p.timeout)
+		el.maxDSCalls = tighter(el.maxDSCalls, p.maxDSCalls)
+		break
+	}
+
+	ctx, el.dsCalls = ds.WithCallLimit(ctx, el.maxDSCalls)
+
+	if el.timeout <= 0 {
+		return ctx, el, func() {}
+	}
+
+	el.deadline = time.Now().Add(el.timeout)
+	ctx, cancel := context.WithDeadline(ctx, el.deadline)
+
+	return ctx, el, cancel
+}
+
+// tighter returns the lower of two limits, where 0 stands for no limit.
+func tighter[T time.Duration | int](a, b T) T {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// err returns the error of an evaluation that exceeded its limits as ErrEvalTimeout or
+// ErrDSCallLimit, and counts it. Other errors, including those of calls whose own deadline
+// expired first, are returned as they are.
+func (el *evalLimit) err(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var limitErr *cerr.AsertoError
+
+	switch {
+	case el.dsCalls.Exceeded():
+		evalLimitsExceeded.WithLabelValues(el.api, limitDSCalls).Inc()
+		limitErr = ErrDSCallLimit.Int("max_ds_calls", el.maxDSCalls)
+
+	case !el.deadline.IsZero() && !time.Now().Before(el.deadline) &&
+		(topdown.IsCancel(errors.Cause(err)) || errors.Is(err, context.DeadlineExceeded)):
+		evalLimitsExceeded.WithLabelValues(el.api, limitTimeout).Inc()
+		limitErr = ErrEvalTimeout.Duration("timeout", el.timeout)
+
+	default:
+		return err
+	}
+
+	if el.path != "" {
+		limitErr = limitErr.Str("path", el.path)
+	}
+
+	// the evaluation error is wrapped by its cause, so that the limit error does not take on its details.
+	return limitErr.Err(errors.Cause(err))
+}
+
+// exceeded reports whether err is the error of an evaluation that exceeded its limits.
+func exceeded(err error) bool {
+	return cerr.Equals(err, ErrEvalTimeout) || cerr.Equals(err, ErrDSCallLimit)
+}
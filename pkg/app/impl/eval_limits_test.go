@@ -0,0 +1,118 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cerr "github.com/aserto-dev/errors"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const slowPolicy = `package todo.GET.todos
+
+default allowed = false
+
+allowed {
+	numbers.range(1, 100000000)[_] == -1
+}
+`
+
+func newTestEvalLimits(t *testing.T, cfg *config.EvalLimitsConfig) *evalLimits {
+	l, err := newEvalLimits(cfg)
+	require.NoError(t, err)
+	return l
+}
+
+func TestEvalLimits(t *testing.T) {
+	l := newTestEvalLimits(t, &config.EvalLimitsConfig{
+		Timeout:    time.Second,
+		APIs:       map[string]time.Duration{"query": 5 * time.Second},
+		MaxDSCalls: 100,
+		Paths: []config.EvalLimitsPath{
+			{Path: "todo.GET.**", Timeout: 100 * time.Millisecond},
+			{Path: "todo.**", Timeout: 10 * time.Second, MaxDSCalls: 10},
+		},
+	})
+
+	limitOf := func(apiName, path string) *evalLimit {
+		_, el, cancel := l.limit(context.Background(), apiName, path)
+		cancel()
+		return el
+	}
+
+	el := limitOf("is", "peoplefinder.GET.users")
+	assert.Equal(t, time.Second, el.timeout)
+	assert.Equal(t, 100, el.maxDSCalls)
+
+	el = limitOf("query", "")
+	assert.Equal(t, 5*time.Second, el.timeout)
+
+	// the first matching path applies, the tighter of its limits and those of the call.
+	el = limitOf("is", "todo.GET.todos")
+	assert.Equal(t, 100*time.Millisecond, el.timeout)
+	assert.Equal(t, 100, el.maxDSCalls)
+
+	el = limitOf("is", "todo.DELETE.todos")
+	assert.Equal(t, time.Second, el.timeout)
+	assert.Equal(t, 10, el.maxDSCalls)
+
+	// without limits evaluations are not bounded.
+	ctx, el, cancel := (*evalLimits)(nil).limit(context.Background(), "is", "todo.GET.todos")
+	defer cancel()
+	assert.Equal(t, context.Background(), ctx)
+	assert.Nil(t, el.err(nil))
+}
+
+func TestEvalLimitsInvalidPath(t *testing.T) {
+	_, err := newEvalLimits(&config.EvalLimitsConfig{
+		Paths: []config.EvalLimitsPath{{Path: "todo.[", Timeout: time.Second}},
+	})
+	assert.Error(t, err)
+}
+
+func TestEvalLimitsTimeout(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, slowPolicy)
+
+	query, err := newQueryCache(10).prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+
+	l := newTestEvalLimits(t, &config.EvalLimitsConfig{Timeout: 10 * time.Millisecond})
+
+	limitCtx, limit, cancel := l.limit(ctx, "is", testPath)
+	defer cancel()
+
+	_, err = evalIs(limitCtx, query, testPath, map[string]interface{}{}, []string{"allowed"})
+	require.Error(t, err)
+
+	err = limit.err(err)
+	assert.True(t, cerr.Equals(err, ErrEvalTimeout))
+	assert.True(t, exceeded(err))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestEvalLimitsCallerDeadline(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, slowPolicy)
+
+	query, err := newQueryCache(10).prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+
+	l := newTestEvalLimits(t, &config.EvalLimitsConfig{Timeout: time.Minute})
+
+	// a caller whose own deadline expires first does not exceed the time budget.
+	callerCtx, callerCancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer callerCancel()
+
+	limitCtx, limit, cancel := l.limit(callerCtx, "is", testPath)
+	defer cancel()
+
+	_, err = evalIs(limitCtx, query, testPath, map[string]interface{}{}, []string{"allowed"})
+	require.Error(t, err)
+	assert.False(t, exceeded(limit.err(err)))
+}
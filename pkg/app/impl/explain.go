@@ -0,0 +1,355 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
+	decisionlog_plugin "github.com/aserto-dev/topaz/decision_log/plugin"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/topdown"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Explain evaluates the decisions of an Is call, bypassing the decision cache, and explains
+// each of them with the rules of the decision that fired or failed and the ds builtin calls
+// they made. Explain calls are not decision logged.
+func (s *AuthorizerServer) Explain(ctx context.Context, req *authorizer.IsRequest) (*topazauthz.ExplainResponse, error) {
+	log := s.logger.With().Str("api", "explain").Logger()
+
+	input, err := s.isInput(ctx, req, &log)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().Interface("input", input).Msg("explaining is")
+
+	policyRuntime, err := s.getRuntime(ctx, req.PolicyInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	evalCtx, calls := ds.WithCalls(ctx, true)
+
+	query, err := s.prepareIs(evalCtx, policyRuntime, req.PolicyContext.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer := newExplainTracer(policyRuntime.GetPluginsManager().GetCompiler(), req.PolicyContext.Path, req.PolicyContext.Decisions)
+
+	limitCtx, limit, cancel := s.limits.limit(evalCtx, decisionlog_plugin.APIIs, req.PolicyContext.Path)
+	defer cancel()
+
+	result, err := evalIs(limitCtx, query, req.PolicyContext.Path, input, req.PolicyContext.Decisions, tracer.evalOptions()...)
+	if err != nil {
+		return nil, limit.err(err)
+	}
+
+	return &topazauthz.ExplainResponse{
+		Decisions:    result.decisions,
+		Explanations: tracer.explain(result.decisions, calls.Records()),
+	}, nil
+}
+
+// explainTracer condenses the trace of an Is evaluation into the rules of each decision,
+// whether they fired, the expression that made them fail, and the ds builtin calls they made.
+type explainTracer struct {
+	// decisions maps the path of the document of each decision to the decision.
+	decisions map[string]string
+
+	// parents maps a query to its parent query, and rules maps the body of a decision rule to the rule.
+	parents map[uint64]uint64
+	rules   map[uint64]*ruleExplanation
+
+	explanations map[string][]*ruleExplanation
+	byRule       map[*ast.Rule]*ruleExplanation
+
+	// dsCalls are the ds builtin calls in evaluation order, with the decision rule that made them.
+	dsCalls []dsCall
+}
+
+type ruleExplanation struct {
+	rule       *ast.Rule
+	evaluated  bool
+	fired      bool
+	failedExpr *ast.Expr
+	dsCalls    []*ds.Call
+}
+
+type dsCall struct {
+	builtin string
+	rule    *ruleExplanation
+}
+
+// newExplainTracer creates a tracer for the decisions of the policy at path, whose rules
+// are taken from compiler so that rules are explained even when they are not evaluated.
+func newExplainTracer(compiler *ast.Compiler, path string, decisions []string) *explainTracer {
+	t := &explainTracer{
+		decisions:    map[string]string{},
+		parents:      map[uint64]uint64{},
+		rules:        map[uint64]*ruleExplanation{},
+		explanations: map[string][]*ruleExplanation{},
+		byRule:       map[*ast.Rule]*ruleExplanation{},
+	}
+
+	for _, d := range decisions {
+		ref := documentRef(path, d)
+		t.decisions[ref.String()] = d
+
+		if compiler == nil {
+			continue
+		}
+		for _, rule := range compiler.GetRulesExact(ref) {
+			t.addRule(d, rule)
+		}
+	}
+
+	return t
+}
+
+// documentRef returns the reference of the document of a decision.
+func documentRef(path, decision string) ast.Ref {
+	ref := ast.Ref{ast.DefaultRootDocument}
+	for _, segment := range strings.Split(path, ".") {
+		ref = append(ref, ast.StringTerm(segment))
+	}
+	return append(ref, ast.StringTerm(decision))
+}
+
+func (t *explainTracer) addRule(decision string, rule *ast.Rule) *ruleExplanation {
+	r := &ruleExplanation{rule: rule}
+	t.byRule[rule] = r
+	t.explanations[decision] = append(t.explanations[decision], r)
+	return r
+}
+
+// evalOptions returns the options of an evaluation traced by t. Early exit is disabled so that
+// every rule of a decision that the rule index does not rule out is evaluated.
+func (t *explainTracer) evalOptions() []rego.EvalOption {
+	return []rego.EvalOption{
+		rego.EvalQueryTracer(t),
+		rego.EvalEarlyExit(false),
+	}
+}
+
+func (t *explainTracer) Enabled() bool {
+	return true
+}
+
+func (t *explainTracer) Config() topdown.TraceConfig {
+	return topdown.TraceConfig{}
+}
+
+func (t *explainTracer) TraceEvent(evt topdown.Event) {
+	if _, ok := t.parents[evt.QueryID]; !ok {
+		t.parents[evt.QueryID] = evt.ParentID
+	}
+
+	switch node := evt.Node.(type) {
+	case *ast.Rule:
+		t.traceRule(evt, node)
+	case *ast.Expr:
+		t.traceExpr(evt, node)
+	}
+}
+
+func (t *explainTracer) traceRule(evt topdown.Event, rule *ast.Rule) {
+	switch evt.Op {
+	case topdown.EnterOp:
+		if r := t.decisionRule(rule); r != nil {
+			r.evaluated = true
+			t.rules[evt.QueryID] = r
+		}
+	case topdown.ExitOp:
+		if r, ok := t.rules[evt.QueryID]; ok {
+			r.fired = true
+		}
+	}
+}
+
+func (t *explainTracer) traceExpr(evt topdown.Event, expr *ast.Expr) {
+	switch evt.Op {
+	case topdown.FailOp:
+		if r, ok := t.rules[evt.QueryID]; ok {
+			r.failedExpr = expr
+		}
+	case topdown.EvalOp:
+		r := t.ruleOf(evt.QueryID)
+		for _, builtin := range dsBuiltins(expr) {
+			t.dsCalls = append(t.dsCalls, dsCall{builtin: builtin, rule: r})
+		}
+	}
+}
+
+// decisionRule returns the explanation of rule when it produces the document of a decision.
+func (t *explainTracer) decisionRule(rule *ast.Rule) *ruleExplanation {
+	if r, ok := t.byRule[rule]; ok {
+		return r
+	}
+
+	// rules of a compiler other than the one the tracer was created with.
+	if rule.Module == nil {
+		return nil
+	}
+
+	decision, ok := t.decisions[rule.Path().String()]
+	if !ok {
+		return nil
+	}
+
+	return t.addRule(decision, rule)
+}
+
+// ruleOf returns the decision rule whose evaluation runs the query, directly or through
+// the rules it depends on, or nil.
+func (t *explainTracer) ruleOf(queryID uint64) *ruleExplanation {
+	for {
+		if r, ok := t.rules[queryID]; ok {
+			return r
+		}
+
+		parent, ok := t.parents[queryID]
+		if !ok || parent == queryID {
+			return nil
+		}
+		queryID = parent
+	}
+}
+
+// dsBuiltins returns the names of the ds builtins called by expr, in the order they are called.
+func dsBuiltins(expr *ast.Expr) []string {
+	var builtins []string
+
+	ast.WalkTerms(expr, func(term *ast.Term) bool {
+		var operator ast.Ref
+
+		switch x := term.Value.(type) {
+		case ast.Call:
+			operator, _ = x[0].Value.(ast.Ref)
+		default:
+			return false
+		}
+
+		if name := operator.String(); strings.HasPrefix(name, "ds.") {
+			builtins = append(builtins, name)
+		}
+		return false
+	})
+
+	// the arguments of a call are evaluated before the call.
+	if expr.IsCall() {
+		if name := expr.Operator().String(); strings.HasPrefix(name, "ds.") {
+			builtins = append(builtins, name)
+		}
+	}
+
+	return builtins
+}
+
+// explain returns the explanation of each decision, attributing the recorded ds builtin calls
+// to the decision rules that made them in the order they were made.
+func (t *explainTracer) explain(decisions []*authorizer.Decision, records []*ds.Call) []*topazauthz.DecisionExplanation {
+	next := map[string]int{}
+	for _, c := range t.dsCalls {
+		i := next[c.builtin]
+		for i < len(records) && records[i].Builtin != c.builtin {
+			i++
+		}
+		if i == len(records) {
+			continue
+		}
+		next[c.builtin] = i + 1
+
+		if c.rule != nil {
+			c.rule.dsCalls = append(c.rule.dsCalls, records[i])
+		}
+	}
+
+	result := make([]*topazauthz.DecisionExplanation, 0, len(decisions))
+	for _, d := range decisions {
+		explanation := &topazauthz.DecisionExplanation{
+			Decision: d.Decision,
+			Is:       d.Is,
+		}
+
+		rules := t.explanations[d.Decision]
+		sort.SliceStable(rules, func(i, j int) bool {
+			return rules[i].rule.Location.Compare(rules[j].rule.Location) < 0
+		})
+
+		for _, r := range rules {
+			explanation.Rules = append(explanation.Rules, r.proto())
+		}
+
+		result = append(result, explanation)
+	}
+
+	return result
+}
+
+func (r *ruleExplanation) proto() *topazauthz.RuleExplanation {
+	rule := &topazauthz.RuleExplanation{
+		Rule:     r.rule.Head.String(),
+		Location: location(r.rule.Location),
+		Default:  r.rule.Default,
+		Fired:    r.fired,
+		Skipped:  !r.evaluated,
+	}
+
+	if !r.fired && r.failedExpr != nil {
+		rule.FailedExpression = expression(r.failedExpr)
+		rule.FailedLocation = location(r.failedExpr.Location)
+	}
+
+	for _, c := range r.dsCalls {
+		call := &topazauthz.BuiltinCall{
+			Builtin: c.Builtin,
+			Error:   c.Error,
+		}
+		call.Args = toValue(c.Args)
+		if c.Result != nil {
+			call.Result = toValue(c.Result)
+		}
+		rule.DsCalls = append(rule.DsCalls, call)
+	}
+
+	return rule
+}
+
+// toValue converts a JSON value of a builtin call, whose numbers may be json.Number, into a proto value.
+func toValue(v interface{}) *structpb.Value {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	value := &structpb.Value{}
+	if err := protojson.Unmarshal(b, value); err != nil {
+		return nil
+	}
+
+	return value
+}
+
+// expression returns the source text of expr, or its string form when the compiler generated it.
+func expression(expr *ast.Expr) string {
+	if expr.Location == nil || len(expr.Location.Text) == 0 {
+		return expr.String()
+	}
+	return strings.TrimSpace(string(expr.Location.Text))
+}
+
+func location(loc *ast.Location) string {
+	if loc == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", loc.File, loc.Row)
+}
@@ -0,0 +1,157 @@
+package impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const explainPolicy = `package todo.GET.todos
+
+default allowed = false
+
+allowed {
+	input.resource.owner == "alice"
+}
+
+allowed {
+	is_admin
+}
+
+is_admin {
+	input.user.role == "admin"
+	input.user.enabled
+}
+
+default visible = true
+`
+
+func explainIs(t *testing.T, input map[string]interface{}, decisions ...string) []*topazauthz.DecisionExplanation {
+	ctx := context.Background()
+	m := newTestManager(t, explainPolicy)
+
+	query, err := newQueryCache(10).prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+
+	tracer := newExplainTracer(m.GetCompiler(), testPath, decisions)
+	result, err := evalIs(ctx, query, testPath, input, decisions, tracer.evalOptions()...)
+	require.NoError(t, err)
+
+	return tracer.explain(result.decisions, nil)
+}
+
+func TestExplainDenied(t *testing.T) {
+	explanations := explainIs(t, map[string]interface{}{
+		InputUser:     map[string]interface{}{"role": "admin", "enabled": false},
+		InputResource: map[string]interface{}{"owner": "bob"},
+	}, "allowed", "visible")
+	require.Len(t, explanations, 2)
+
+	allowed := explanations[0]
+	assert.Equal(t, "allowed", allowed.Decision)
+	assert.False(t, allowed.Is)
+	require.Len(t, allowed.Rules, 3)
+
+	assert.Equal(t, "allowed = false", allowed.Rules[0].Rule)
+	assert.Equal(t, "policy.rego:3", allowed.Rules[0].Location)
+	assert.True(t, allowed.Rules[0].Default)
+	assert.True(t, allowed.Rules[0].Fired)
+
+	// the rule index rules out the owner rule without evaluating it.
+	assert.Equal(t, "policy.rego:5", allowed.Rules[1].Location)
+	assert.True(t, allowed.Rules[1].Skipped)
+	assert.False(t, allowed.Rules[1].Fired)
+
+	assert.Equal(t, "policy.rego:9", allowed.Rules[2].Location)
+	assert.False(t, allowed.Rules[2].Fired)
+	assert.Equal(t, "is_admin", allowed.Rules[2].FailedExpression)
+	assert.Equal(t, "policy.rego:10", allowed.Rules[2].FailedLocation)
+
+	visible := explanations[1]
+	assert.True(t, visible.Is)
+	require.Len(t, visible.Rules, 1)
+	assert.True(t, visible.Rules[0].Fired)
+}
+
+func TestExplainAllowed(t *testing.T) {
+	explanations := explainIs(t, map[string]interface{}{
+		InputUser:     map[string]interface{}{"role": "admin", "enabled": true},
+		InputResource: map[string]interface{}{"owner": "alice"},
+	}, "allowed")
+	require.Len(t, explanations, 1)
+
+	allowed := explanations[0]
+	assert.True(t, allowed.Is)
+	require.Len(t, allowed.Rules, 3)
+
+	for _, r := range allowed.Rules[1:] {
+		assert.True(t, r.Fired, r.Location)
+		assert.False(t, r.Skipped, r.Location)
+		assert.Empty(t, r.FailedExpression, r.Location)
+	}
+}
+
+func TestDSBuiltins(t *testing.T) {
+	tests := map[string][]string{
+		`ds.check_relation({"relation": "owner"})`:              {"ds.check_relation"},
+		`x := ds.user({"key": "alice"})`:                        {"ds.user"},
+		`ds.check_relation({"subject": ds.identity({})["id"]})`: {"ds.identity", "ds.check_relation"},
+		`input.user.enabled`:                                    nil,
+		`count(input.resource) > 0`:                             nil,
+	}
+
+	for body, expected := range tests {
+		t.Run(body, func(t *testing.T) {
+			assert.Equal(t, expected, dsBuiltins(ast.MustParseBody(body)[0]))
+		})
+	}
+}
+
+func TestExplainDSCalls(t *testing.T) {
+	tracer := newExplainTracer(nil, testPath, []string{"allowed"})
+
+	rule := ast.MustParseRule(`allowed { ds.check_relation({}) }`)
+	rule.Module = &ast.Module{Package: ast.MustParsePackage("package " + testPath)}
+	r := tracer.decisionRule(rule)
+	require.NotNil(t, r)
+
+	tracer.dsCalls = []dsCall{
+		{builtin: "ds.identity"},
+		{builtin: "ds.check_relation", rule: r},
+	}
+
+	records := []*ds.Call{
+		{Builtin: "ds.identity", Args: map[string]interface{}{}, Result: "alice"},
+		{Builtin: "ds.check_relation", Args: map[string]interface{}{"relation": "owner"}, Error: "not found"},
+	}
+
+	explanations := tracer.explain([]*authorizer.Decision{{Decision: "allowed"}}, records)
+	require.Len(t, explanations[0].Rules, 1)
+
+	calls := explanations[0].Rules[0].DsCalls
+	require.Len(t, calls, 1)
+	assert.Equal(t, "ds.check_relation", calls[0].Builtin)
+	assert.Equal(t, "owner", calls[0].Args.GetStructValue().AsMap()["relation"])
+	assert.Nil(t, calls[0].Result)
+	assert.Equal(t, "not found", calls[0].Error)
+}
+
+func TestExplainGeneratedExpression(t *testing.T) {
+	rule := ast.MustParseRule(`allowed { input.user.enabled }`)
+
+	// expressions generated by the compiler have no location.
+	expr := ast.MustParseExpr(`input.user.enabled`)
+	expr.Location = nil
+
+	r := &ruleExplanation{rule: rule, evaluated: true, failedExpr: expr}
+
+	explanation := r.proto()
+	assert.Equal(t, "input.user.enabled", explanation.FailedExpression)
+	assert.Empty(t, explanation.FailedLocation)
+}
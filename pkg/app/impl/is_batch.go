@@ -0,0 +1,238 @@
+package impl
+
+import (
+	"context"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	"github.com/aserto-dev/go-authorizer/pkg/aerr"
+	runtime "github.com/aserto-dev/runtime"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	"github.com/aserto-dev/topaz/builtins/edge/ds"
+	decisionlog_plugin "github.com/aserto-dev/topaz/decision_log/plugin"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// batchQuery is the prepared query of a policy path, or the error preparing it.
+type batchQuery struct {
+	query *rego.PreparedEvalQuery
+	err   error
+}
+
+// batch is a validated IsBatch request, with its identity resolved and its policy paths prepared.
+type batch struct {
+	req           *topazauthz.IsBatchRequest
+	rt            *runtime.Runtime
+	queries       map[string]*batchQuery
+	limits        *evalLimits
+	userInput     interface{}
+	identityInput interface{}
+}
+
+// batchItem is the result of an item of a batch, with the decision record to log when decisions are logged.
+type batchItem struct {
+	result   *topazauthz.IsBatchResult
+	decision *api.Decision
+}
+
+// IsBatch evaluates the decisions of the policy context for each of the items of the request.
+//
+// The identity is resolved once and every distinct policy path is prepared once, after
+// which the items are evaluated concurrently, up to the configured limit. An item that
+// fails to evaluate carries its error in its result, the other items are not affected.
+func (s *AuthorizerServer) IsBatch(ctx context.Context, req *topazauthz.IsBatchRequest) (*topazauthz.IsBatchResponse, error) {
+	log := s.logger.With().Str("api", "is_batch").Logger()
+
+	resp := &topazauthz.IsBatchResponse{}
+
+	b, err := s.prepareBatch(ctx, req, &log)
+	if err != nil {
+		return resp, err
+	}
+
+	items := s.evalBatch(ctx, b)
+
+	resp.Results = make([]*topazauthz.IsBatchResult, len(items))
+	for i, item := range items {
+		b.logItem(ctx, item)
+		resp.Results[i] = item.result
+	}
+
+	return resp, nil
+}
+
+// prepareBatch validates a batch request, resolves its identity and prepares its policy paths.
+func (s *AuthorizerServer) prepareBatch(ctx context.Context, req *topazauthz.IsBatchRequest, log *zerolog.Logger) (*batch, error) {
+	if req.PolicyContext == nil {
+		return nil, aerr.ErrInvalidArgument.Msg("policy context not set")
+	}
+
+	if len(req.PolicyContext.Decisions) == 0 {
+		return nil, aerr.ErrInvalidArgument.Msg("policy context decisions not set")
+	}
+
+	if len(req.Items) == 0 {
+		return nil, aerr.ErrInvalidArgument.Msg("items not set")
+	}
+
+	if maxItems := s.cfg.Authorizer.IsBatch.MaxItems; maxItems > 0 && len(req.Items) > maxItems {
+		return nil, aerr.ErrInvalidArgument.Msgf("too many items [%d], the maximum is [%d]", len(req.Items), maxItems)
+	}
+
+	if req.IdentityContext == nil {
+		return nil, aerr.ErrInvalidArgument.Msg("identity context not set")
+	}
+
+	if req.IdentityContext.Type == api.IdentityType_IDENTITY_TYPE_UNKNOWN {
+		return nil, aerr.ErrInvalidArgument.Msg("identity type UNKNOWN")
+	}
+
+	user, err := s.getUserFromIdentityContext(ctx, req.IdentityContext)
+	if err != nil {
+		log.Error().Err(err).Interface("req", req).Msg("failed to resolve identity context")
+		return nil, aerr.ErrUserNotFound.WithGRPCStatus(codes.NotFound).Msg("failed to resolve identity context")
+	}
+
+	policyRuntime, err := s.getRuntime(ctx, req.PolicyInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := map[string]*batchQuery{}
+	for _, item := range req.Items {
+		path := batchItemPath(req, item)
+		if _, ok := queries[path]; ok || path == "" {
+			continue
+		}
+
+		query, err := s.prepareIs(ctx, policyRuntime, path)
+		queries[path] = &batchQuery{query: query, err: err}
+	}
+
+	return &batch{
+		req:           req,
+		rt:            policyRuntime,
+		queries:       queries,
+		limits:        s.limits,
+		userInput:     convert(user),
+		identityInput: convert(req.IdentityContext),
+	}, nil
+}
+
+// evalBatch evaluates the items of a batch concurrently, up to the configured limit.
+func (s *AuthorizerServer) evalBatch(ctx context.Context, b *batch) []*batchItem {
+	items := make([]*batchItem, len(b.req.Items))
+
+	g := &errgroup.Group{}
+	if maxConcurrency := s.cfg.Authorizer.IsBatch.MaxConcurrency; maxConcurrency > 0 {
+		g.SetLimit(maxConcurrency)
+	}
+
+	for i, item := range b.req.Items {
+		i, item := i, item
+		g.Go(func() error {
+			items[i] = b.evalItem(ctx, item)
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return items
+}
+
+// evalItem evaluates the decisions of a single item of a batch.
+func (b *batch) evalItem(ctx context.Context, item *topazauthz.IsBatchItem) *batchItem {
+	result, d, err := b.evalIs(ctx, item)
+	if err != nil {
+		return &batchItem{result: &topazauthz.IsBatchResult{Error: status.Convert(err).Proto()}}
+	}
+
+	return &batchItem{
+		result: &topazauthz.IsBatchResult{
+			Decisions:   result.decisions,
+			Reasons:     result.companions.reasons(),
+			Obligations: result.companions.obligations(),
+		},
+		decision: d,
+	}
+}
+
+func (b *batch) evalIs(ctx context.Context, item *topazauthz.IsBatchItem) (*isResult, *api.Decision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, status.FromContextError(err).Err()
+	}
+
+	path := batchItemPath(b.req, item)
+	if path == "" {
+		return nil, nil, aerr.ErrInvalidArgument.Msg("item path not set")
+	}
+
+	bq := b.queries[path]
+	if bq.err != nil {
+		return nil, nil, bq.err
+	}
+
+	policyContext := proto.Clone(b.req.PolicyContext).(*api.PolicyContext)
+	policyContext.Path = path
+
+	resourceContext := item.ResourceContext
+	if resourceContext == nil {
+		resourceContext = &structpb.Struct{Fields: make(map[string]*structpb.Value)}
+	}
+
+	input := map[string]interface{}{
+		InputUser:     b.userInput,
+		InputIdentity: b.identityInput,
+		InputPolicy:   policyContext,
+		InputResource: resourceContext,
+	}
+
+	evalCtx, calls := ds.WithCalls(ctx, recordDSCalls(b.rt, decisionlog_plugin.APIIs))
+	start := time.Now()
+
+	limitCtx, limit, cancel := b.limits.limit(evalCtx, decisionlog_plugin.APIIs, path)
+	result, err := evalIs(limitCtx, bq.query, path, input, policyContext.Decisions)
+	cancel()
+	elapsed := time.Since(start)
+
+	if err != nil {
+		return nil, nil, limit.err(err)
+	}
+
+	if decisionLogger(b.rt, decisionlog_plugin.APIIs) == nil {
+		return result, nil, nil
+	}
+
+	d := newDecision(ctx, decisionlog_plugin.APIIs, path,
+		policyContext, b.req.PolicyInstance, b.req.IdentityContext, resourceContext, input, result.outcomes)
+	annotateEval(d, elapsed, calls)
+	annotateCompanions(d, result.companions)
+
+	return result, d, nil
+}
+
+// logItem logs the decisions of an evaluated item, when decisions are logged.
+func (b *batch) logItem(ctx context.Context, item *batchItem) {
+	if item.decision == nil {
+		return
+	}
+
+	if dlPlugin := decisionLogger(b.rt, decisionlog_plugin.APIIs); dlPlugin != nil {
+		dlPlugin.Log(ctx, item.decision)
+	}
+}
+
+// batchItemPath returns the policy path of an item, which defaults to the path of the policy context.
+func batchItemPath(req *topazauthz.IsBatchRequest, item *topazauthz.IsBatchItem) string {
+	if item.GetPath() != "" {
+		return item.GetPath()
+	}
+	return req.PolicyContext.GetPath()
+}
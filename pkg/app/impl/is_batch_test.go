@@ -0,0 +1,199 @@
+package impl
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	runtime "github.com/aserto-dev/runtime"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/aserto-dev/topaz/resolvers"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const batchWaitPolicy = `package batch.wait
+
+allowed = batch_test.wait(input.resource.id)
+`
+
+// batchActive counts the evaluations of batch_test.wait in progress, batchMaxActive the most seen at once.
+var (
+	batchActive     int32
+	batchMaxActive  int32
+	registerBatchFn sync.Once
+)
+
+// registerBatchWait registers the batch_test.wait builtin, which holds an evaluation for a while
+// and records how many evaluations hold it at once.
+func registerBatchWait() {
+	registerBatchFn.Do(func() {
+		rego.RegisterBuiltin1(&rego.Function{
+			Name: "batch_test.wait",
+			Decl: types.NewFunction(types.Args(types.A), types.B),
+		}, func(_ rego.BuiltinContext, _ *ast.Term) (*ast.Term, error) {
+			active := atomic.AddInt32(&batchActive, 1)
+			defer atomic.AddInt32(&batchActive, -1)
+
+			for {
+				most := atomic.LoadInt32(&batchMaxActive)
+				if active <= most || atomic.CompareAndSwapInt32(&batchMaxActive, most, active) {
+					break
+				}
+			}
+
+			time.Sleep(10 * time.Millisecond)
+			return ast.BooleanTerm(true), nil
+		})
+	})
+}
+
+// testRuntimeResolver resolves every policy instance to the same runtime.
+type testRuntimeResolver struct {
+	resolvers.RuntimeResolver
+	rt *runtime.Runtime
+}
+
+func (r *testRuntimeResolver) RuntimeFromContext(ctx context.Context, policyName, instanceLabel string) (*runtime.Runtime, error) {
+	return r.rt, nil
+}
+
+func newBatchServer(t *testing.T, cfg *config.Common, policies map[string]string) *AuthorizerServer {
+	ctx := context.Background()
+	logger := zerolog.Nop()
+
+	rt, cleanup, err := runtime.NewRuntime(ctx, &logger, &runtime.Config{})
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	m := rt.GetPluginsManager()
+	require.NoError(t, m.Init(ctx))
+	for id, policy := range policies {
+		upsertPolicy(t, m, id, policy)
+	}
+
+	r := resolvers.New()
+	r.SetRuntimeResolver(&testRuntimeResolver{rt: rt})
+
+	return &AuthorizerServer{cfg: cfg, logger: &logger, resolver: r, queries: newQueryCache(10)}
+}
+
+func batchRequest(path string, items ...*topazauthz.IsBatchItem) *topazauthz.IsBatchRequest {
+	return &topazauthz.IsBatchRequest{
+		IdentityContext: &api.IdentityContext{Type: api.IdentityType_IDENTITY_TYPE_NONE},
+		PolicyContext:   &api.PolicyContext{Path: path, Decisions: []string{"allowed"}},
+		Items:           items,
+	}
+}
+
+func testBatchItem(path, owner string) *topazauthz.IsBatchItem {
+	resource, _ := structpb.NewStruct(map[string]interface{}{"owner": owner, "id": owner})
+	return &topazauthz.IsBatchItem{Path: path, ResourceContext: resource}
+}
+
+func TestIsBatchMaxItems(t *testing.T) {
+	cfg := &config.Common{}
+	cfg.Authorizer.IsBatch.MaxItems = 2
+	s := newBatchServer(t, cfg, map[string]string{"policy.rego": testPolicy("alice")})
+
+	_, err := s.IsBatch(context.Background(), batchRequest(testPath,
+		testBatchItem("", "alice"),
+		testBatchItem("", "bob"),
+		testBatchItem("", "carol"),
+	))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := s.IsBatch(context.Background(), batchRequest(testPath, testBatchItem("", "alice"), testBatchItem("", "bob")))
+	require.NoError(t, err)
+	assert.Len(t, resp.Results, 2)
+}
+
+func TestIsBatchItemErrors(t *testing.T) {
+	s := newBatchServer(t, &config.Common{}, map[string]string{
+		"policy.rego": testPolicy("alice"),
+		"broken.rego": treePolicies["broken.rego"],
+	})
+
+	resp, err := s.IsBatch(context.Background(), batchRequest(testPath,
+		testBatchItem("", "alice"),
+		testBatchItem("tree.broken", "alice"),
+		testBatchItem("todo GET todos", "alice"),
+		testBatchItem("", "bob"),
+	))
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 4)
+
+	// an item that fails carries its error, the other items are not affected.
+	assert.Nil(t, resp.Results[0].Error)
+	assert.True(t, resp.Results[0].Decisions[0].Is)
+
+	assert.NotNil(t, resp.Results[1].Error)
+	assert.Empty(t, resp.Results[1].Decisions)
+
+	assert.NotNil(t, resp.Results[2].Error)
+
+	assert.Nil(t, resp.Results[3].Error)
+	assert.False(t, resp.Results[3].Decisions[0].Is)
+
+	// without a path in the policy context, each item must set its own.
+	resp, err = s.IsBatch(context.Background(), batchRequest("", testBatchItem("", "alice"), testBatchItem(testPath, "alice")))
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+
+	assert.Equal(t, int32(codes.InvalidArgument), resp.Results[0].Error.GetCode())
+	assert.Nil(t, resp.Results[1].Error)
+}
+
+func TestIsBatchCanceled(t *testing.T) {
+	s := newBatchServer(t, &config.Common{}, map[string]string{"policy.rego": testPolicy("alice")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp, err := s.IsBatch(ctx, batchRequest(testPath, testBatchItem("", "alice"), testBatchItem("", "bob")))
+	require.NoError(t, err)
+
+	for _, result := range resp.Results {
+		assert.Equal(t, int32(codes.Canceled), result.Error.GetCode())
+	}
+}
+
+func TestIsBatchConcurrency(t *testing.T) {
+	registerBatchWait()
+
+	for _, maxConcurrency := range []int32{1, 3} {
+		cfg := &config.Common{}
+		cfg.Authorizer.IsBatch.MaxConcurrency = int(maxConcurrency)
+		s := newBatchServer(t, cfg, map[string]string{"wait.rego": batchWaitPolicy})
+
+		items := make([]*topazauthz.IsBatchItem, 8)
+		for i := range items {
+			items[i] = testBatchItem("", "alice")
+		}
+
+		atomic.StoreInt32(&batchMaxActive, 0)
+
+		resp, err := s.IsBatch(context.Background(), batchRequest("batch.wait", items...))
+		require.NoError(t, err)
+		require.Len(t, resp.Results, len(items))
+
+		for _, result := range resp.Results {
+			require.Nil(t, result.Error)
+			assert.True(t, result.Decisions[0].Is)
+		}
+
+		assert.Positive(t, atomic.LoadInt32(&batchMaxActive))
+		assert.LessOrEqual(t, atomic.LoadInt32(&batchMaxActive), maxConcurrency)
+	}
+}
@@ -0,0 +1,140 @@
+package impl
+
+import (
+	"context"
+	"sync"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/plugins"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/storage"
+)
+
+// queryCache holds the queries prepared for the Is and DecisionTree calls, per plugins
+// manager of a runtime.
+//
+// A prepared query stays valid as long as the compiler it was prepared with is the active
+// compiler of its manager. The compiler only changes when a bundle activates, at which point
+// the queries of the manager are dropped, by a compiler trigger registered when the manager is
+// created. The queries of a manager that was not registered are not cached.
+type queryCache struct {
+	size int
+
+	mu       sync.Mutex
+	managers map[*plugins.Manager]*preparedQueries
+}
+
+// preparedQueries are the queries prepared with the compiler of a plugins manager, by query statement.
+type preparedQueries struct {
+	mu       sync.RWMutex
+	compiler *ast.Compiler
+	queries  map[string]*rego.PreparedEvalQuery
+}
+
+// newQueryCache creates a cache holding up to size prepared queries per plugins manager.
+// A size of 0 disables the cache.
+func newQueryCache(size int) *queryCache {
+	return &queryCache{
+		size:     size,
+		managers: map[*plugins.Manager]*preparedQueries{},
+	}
+}
+
+// prepare returns the query prepared for queryStmt with the active compiler of m,
+// preparing and caching it when it is not cached yet.
+func (c *queryCache) prepare(ctx context.Context, m *plugins.Manager, queryStmt string) (*rego.PreparedEvalQuery, error) {
+	compiler := m.GetCompiler()
+
+	if c.size <= 0 {
+		return prepareQuery(ctx, m, compiler, queryStmt)
+	}
+
+	pq := c.forManager(m)
+	if pq == nil {
+		return prepareQuery(ctx, m, compiler, queryStmt)
+	}
+
+	if query, ok := pq.get(compiler, queryStmt); ok {
+		return query, nil
+	}
+
+	query, err := prepareQuery(ctx, m, compiler, queryStmt)
+	if err != nil {
+		return nil, err
+	}
+
+	pq.put(compiler, queryStmt, query, c.size)
+
+	return query, nil
+}
+
+// register registers the compiler trigger that drops the queries prepared for m when a bundle activates.
+func (c *queryCache) register(m *plugins.Manager) {
+	if c.size <= 0 {
+		return
+	}
+
+	pq := &preparedQueries{}
+
+	c.mu.Lock()
+	c.managers[m] = pq
+	c.mu.Unlock()
+
+	m.RegisterCompilerTrigger(func(storage.Transaction) {
+		pq.reset()
+	})
+}
+
+func (c *queryCache) forManager(m *plugins.Manager) *preparedQueries {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.managers[m]
+}
+
+func (pq *preparedQueries) get(compiler *ast.Compiler, queryStmt string) (*rego.PreparedEvalQuery, bool) {
+	pq.mu.RLock()
+	defer pq.mu.RUnlock()
+
+	if pq.compiler != compiler {
+		return nil, false
+	}
+
+	query, ok := pq.queries[queryStmt]
+	return query, ok
+}
+
+// put caches a query prepared with compiler, dropping the queries prepared with another
+// compiler. The queries are also dropped when the cache is full.
+func (pq *preparedQueries) put(compiler *ast.Compiler, queryStmt string, query *rego.PreparedEvalQuery, size int) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	if pq.compiler != compiler || len(pq.queries) >= size {
+		pq.compiler = compiler
+		pq.queries = map[string]*rego.PreparedEvalQuery{}
+	}
+
+	pq.queries[queryStmt] = query
+}
+
+func (pq *preparedQueries) reset() {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+
+	pq.compiler = nil
+	pq.queries = nil
+}
+
+func prepareQuery(ctx context.Context, m *plugins.Manager, compiler *ast.Compiler, queryStmt string) (*rego.PreparedEvalQuery, error) {
+	query, err := rego.New(
+		rego.Compiler(compiler),
+		rego.Store(m.Store),
+		rego.Query(queryStmt),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &query, nil
+}
@@ -0,0 +1,173 @@
+package impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-policy-agent/opa/plugins"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/storage/inmem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPath      = "todo.GET.todos"
+	testQueryStmt = "x = data." + testPath
+)
+
+func testPolicy(owner string) string {
+	return `package todo.GET.todos
+
+default allowed = false
+
+allowed {
+	input.resource.owner == "` + owner + `"
+}
+`
+}
+
+// newTestManager creates a plugins manager with the policy loaded in its store.
+func newTestManager(tb testing.TB, policy string) *plugins.Manager {
+	ctx := context.Background()
+
+	m, err := plugins.New(nil, "test", inmem.New())
+	require.NoError(tb, err)
+	require.NoError(tb, m.Init(ctx))
+
+	upsertPolicy(tb, m, "policy.rego", policy)
+
+	return m
+}
+
+// upsertPolicy writes the policy to the store of m, which recompiles its policies as a bundle activation does.
+func upsertPolicy(tb testing.TB, m *plugins.Manager, id, policy string) {
+	ctx := context.Background()
+
+	err := storage.Txn(ctx, m.Store, storage.WriteParams, func(txn storage.Transaction) error {
+		return m.Store.UpsertPolicy(ctx, txn, id, []byte(policy))
+	})
+	require.NoError(tb, err)
+}
+
+func isAllowed(t *testing.T, c *queryCache, m *plugins.Manager, owner string) bool {
+	ctx := context.Background()
+
+	query, err := c.prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+
+	input := map[string]interface{}{InputResource: map[string]interface{}{"owner": owner}}
+	result, err := evalIs(ctx, query, testPath, input, []string{"allowed"})
+	require.NoError(t, err)
+
+	return result.decisions[0].Is
+}
+
+func newTestQueryCache(m *plugins.Manager, size int) *queryCache {
+	c := newQueryCache(size)
+	c.register(m)
+	return c
+}
+
+func TestQueryCache(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, testPolicy("alice"))
+	c := newTestQueryCache(m, 10)
+
+	first, err := c.prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+
+	second, err := c.prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	assert.True(t, isAllowed(t, c, m, "alice"))
+	assert.False(t, isAllowed(t, c, m, "bob"))
+
+	upsertPolicy(t, m, "policy.rego", testPolicy("bob"))
+
+	third, err := c.prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+
+	assert.False(t, isAllowed(t, c, m, "alice"))
+	assert.True(t, isAllowed(t, c, m, "bob"))
+}
+
+func TestQueryCacheSize(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, testPolicy("alice"))
+
+	disabled := newTestQueryCache(m, 0)
+
+	first, err := disabled.prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+
+	second, err := disabled.prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+
+	full := newTestQueryCache(m, 1)
+
+	_, err = full.prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+
+	_, err = full.prepare(ctx, m, "x = data.todo")
+	require.NoError(t, err)
+
+	assert.Len(t, full.managers[m].queries, 1)
+}
+
+func TestQueryCacheError(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	c := newTestQueryCache(m, 10)
+
+	_, err := c.prepare(context.Background(), m, "x = data.todo[")
+	assert.Error(t, err)
+	assert.Empty(t, c.managers[m].queries)
+}
+
+func TestQueryCacheUnregistered(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, testPolicy("alice"))
+	c := newQueryCache(10)
+
+	// the queries of a manager whose compiler trigger is not registered are not cached.
+	first, err := c.prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+
+	second, err := c.prepare(ctx, m, testQueryStmt)
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+	assert.Empty(t, c.managers)
+}
+
+func BenchmarkIs(b *testing.B) {
+	ctx := context.Background()
+	m := newTestManager(b, testPolicy("alice"))
+
+	input := map[string]interface{}{InputResource: map[string]interface{}{"owner": "alice"}}
+	decisions := []string{"allowed"}
+
+	benchmarks := map[string]*queryCache{
+		"uncached": newQueryCache(0),
+		"cached":   newTestQueryCache(m, 10),
+	}
+
+	for name, c := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				query, err := c.prepare(ctx, m, testQueryStmt)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				if _, err := evalIs(ctx, query, testPath, input, decisions); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
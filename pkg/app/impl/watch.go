@@ -0,0 +1,182 @@
+package impl
+
+import (
+	"sync"
+	"time"
+
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	"github.com/open-policy-agent/opa/plugins"
+	"github.com/open-policy-agent/opa/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/protobuf/proto"
+)
+
+var watchStreams = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "topaz",
+	Subsystem: "watch",
+	Name:      "streams",
+	Help:      "Number of open Watch streams.",
+})
+
+// Watch evaluates the decisions of the items of the request, as IsBatch does, and sends them
+// again every time the results of some items change.
+//
+// The decisions are evaluated again when a bundle activates in the runtime of the request,
+// when the edge directory changes and at the configured interval. Only the decisions of the
+// items whose results changed are logged. The stream ends when the request is invalid, its
+// identity can no longer be resolved or a response cannot be sent.
+func (s *AuthorizerServer) Watch(req *topazauthz.IsBatchRequest, stream topazauthz.Authorizer_WatchServer) error {
+	ctx := stream.Context()
+	log := s.logger.With().Str("api", "watch").Logger()
+
+	watchStreams.Inc()
+	defer watchStreams.Dec()
+
+	var directory <-chan struct{}
+	if watcher := s.resolver.GetDirectoryWatcher(); watcher != nil {
+		changes, stop := watcher.Watch()
+		defer stop()
+		directory = changes
+	}
+
+	var interval <-chan time.Time
+	if d := s.cfg.Authorizer.Watch.Interval; d > 0 {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		interval = ticker.C
+	}
+
+	var (
+		manager     *plugins.Manager
+		bundles     <-chan struct{}
+		stopBundles = func() {}
+		previous    []*topazauthz.IsBatchResult
+	)
+	defer func() { stopBundles() }()
+
+	trigger := topazauthz.WatchTrigger_WATCH_TRIGGER_INITIAL
+
+	for {
+		b, err := s.prepareBatch(ctx, req, &log)
+		if err != nil {
+			return err
+		}
+
+		// the runtime of a policy instance is replaced when its configuration changes.
+		if m := b.rt.GetPluginsManager(); m != manager {
+			stopBundles()
+			manager = m
+			bundles, stopBundles = s.bundles.watch(m)
+		}
+
+		items := s.evalBatch(ctx, b)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		resp := &topazauthz.WatchResponse{
+			Results: make([]*topazauthz.IsBatchResult, len(items)),
+			Trigger: trigger,
+		}
+		for i, item := range items {
+			resp.Results[i] = item.result
+		}
+
+		resp.Changed = changedResults(previous, resp.Results)
+		for _, i := range resp.Changed {
+			b.logItem(ctx, items[i])
+		}
+
+		if len(resp.Changed) > 0 {
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+
+		previous = resp.Results
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-bundles:
+			trigger = topazauthz.WatchTrigger_WATCH_TRIGGER_BUNDLE
+		case <-directory:
+			trigger = topazauthz.WatchTrigger_WATCH_TRIGGER_DIRECTORY
+		case <-interval:
+			trigger = topazauthz.WatchTrigger_WATCH_TRIGGER_INTERVAL
+		}
+
+		log.Trace().Stringer("trigger", trigger).Msg("evaluating watched decisions")
+	}
+}
+
+// changedResults returns the indexes of the results that differ from the previous results,
+// every index when there are no previous results.
+func changedResults(previous, results []*topazauthz.IsBatchResult) []int32 {
+	changed := []int32{}
+	for i, result := range results {
+		if i < len(previous) && proto.Equal(previous[i], result) {
+			continue
+		}
+		changed = append(changed, int32(i))
+	}
+	return changed
+}
+
+// bundleWatchers notifies watches of the bundle activations of the plugins managers of the runtimes,
+// through a compiler trigger registered when a manager is created. The watches of a manager that
+// was not registered are never notified.
+type bundleWatchers struct {
+	mu       sync.Mutex
+	managers map[*plugins.Manager]map[chan struct{}]struct{}
+}
+
+func newBundleWatchers() *bundleWatchers {
+	return &bundleWatchers{managers: map[*plugins.Manager]map[chan struct{}]struct{}{}}
+}
+
+// register registers the compiler trigger that notifies the watches of m when a bundle activates.
+func (w *bundleWatchers) register(m *plugins.Manager) {
+	m.RegisterCompilerTrigger(func(storage.Transaction) {
+		w.notify(m)
+	})
+}
+
+// watch returns a channel that receives a value after a bundle activates in m, activations made
+// before the value is received are coalesced, and a function that ends the watch.
+func (w *bundleWatchers) watch(m *plugins.Manager) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	watchers, ok := w.managers[m]
+	if !ok {
+		watchers = map[chan struct{}]struct{}{}
+		w.managers[m] = watchers
+	}
+	watchers[ch] = struct{}{}
+
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		delete(w.managers[m], ch)
+		if len(w.managers[m]) == 0 {
+			delete(w.managers, m)
+		}
+	}
+}
+
+func (w *bundleWatchers) notify(m *plugins.Manager) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for ch := range w.managers[m] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
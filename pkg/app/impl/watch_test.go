@@ -0,0 +1,79 @@
+package impl
+
+import (
+	"testing"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/status"
+)
+
+func received(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestBundleWatchers(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	w := newBundleWatchers()
+	w.register(m)
+
+	first, stopFirst := w.watch(m)
+	second, stopSecond := w.watch(m)
+
+	assert.False(t, received(first))
+
+	// activations are coalesced until they are received.
+	upsertPolicy(t, m, "policy.rego", testPolicy("bob"))
+	upsertPolicy(t, m, "policy.rego", testPolicy("carol"))
+
+	assert.True(t, received(first))
+	assert.False(t, received(first))
+	assert.True(t, received(second))
+
+	stopFirst()
+	upsertPolicy(t, m, "policy.rego", testPolicy("dave"))
+
+	assert.False(t, received(first))
+	assert.True(t, received(second))
+
+	// a manager is forgotten once its last watch ends.
+	stopSecond()
+	assert.Empty(t, w.managers)
+
+	third, stopThird := w.watch(m)
+	defer stopThird()
+
+	upsertPolicy(t, m, "policy.rego", testPolicy("erin"))
+	assert.True(t, received(third))
+}
+
+func TestBundleWatchersUnregistered(t *testing.T) {
+	m := newTestManager(t, testPolicy("alice"))
+	w := newBundleWatchers()
+
+	ch, stop := w.watch(m)
+	defer stop()
+
+	upsertPolicy(t, m, "policy.rego", testPolicy("bob"))
+	assert.False(t, received(ch))
+}
+
+func TestChangedResults(t *testing.T) {
+	allowed := func(is bool) *topazauthz.IsBatchResult {
+		return &topazauthz.IsBatchResult{Decisions: []*authorizer.Decision{{Decision: "allowed", Is: is}}}
+	}
+
+	results := []*topazauthz.IsBatchResult{allowed(true), allowed(false), {Error: &status.Status{Code: 5}}}
+
+	assert.Equal(t, []int32{0, 1, 2}, changedResults(nil, results))
+
+	next := []*topazauthz.IsBatchResult{allowed(true), allowed(true), {Error: &status.Status{Code: 5}}}
+	assert.Equal(t, []int32{1}, changedResults(results, next))
+	assert.Empty(t, changedResults(next, next))
+}
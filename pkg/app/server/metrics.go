@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/aserto-dev/logger"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog"
+)
+
+// newMetricsServer creates the server of the /metrics endpoint, on a listener of its own so that
+// metrics are not exposed along with the API. It returns nil when no metrics listen address is
+// configured or when the registry cannot be gathered.
+func newMetricsServer(log *zerolog.Logger, cfg *config.Common, registry promclient.Registerer) *http.Server {
+	if cfg.API.Metrics.ListenAddress == "" {
+		return nil
+	}
+
+	gatherer, ok := registry.(promclient.Gatherer)
+	if !ok {
+		log.Warn().Msg("metrics registry cannot be gathered, metrics are not served")
+		return nil
+	}
+
+	newLogger := log.With().Str("source", "metrics").Logger()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+
+	return &http.Server{
+		ErrorLog:          logger.NewSTDLogger(&newLogger),
+		Addr:              cfg.API.Metrics.ListenAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: cfg.API.Gateway.ReadHeaderTimeout,
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"google.golang.org/grpc"
 
 	authz "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
 )
 
 // GRPCRegistrations represents a function that can register API implementations to the GRPC server.
@@ -25,5 +26,6 @@ func CoreServiceRegistrations(
 ) GRPCRegistrations {
 	return func(srv *grpc.Server) {
 		authz.RegisterAuthorizerServer(srv, implAuthorizerServer)
+		topazauthz.RegisterAuthorizerServer(srv, implAuthorizerServer)
 	}
 }
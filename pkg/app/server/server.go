@@ -12,6 +12,7 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
+	promclient "github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
@@ -38,6 +39,7 @@ type Server struct {
 	grpcRegistrations GRPCRegistrations
 	gtwServer         *http.Server
 	healthServer      *HealthServer
+	metricsServer     *http.Server
 
 	gtwMux               *runtime.ServeMux
 	handlerRegistrations HandlerRegistrations
@@ -54,7 +56,7 @@ func NewServer(
 	handlerRegistrations HandlerRegistrations,
 	gtwServer *http.Server,
 	gtwMux *runtime.ServeMux,
-
+	registry promclient.Registerer,
 ) (*Server, func(), error) {
 
 	newLogger := logger.With().Str("component", "api.edge-server").Logger()
@@ -69,6 +71,7 @@ func NewServer(
 		grpcRegistrations:    grpcRegistrations,
 		gtwServer:            gtwServer,
 		healthServer:         healthServer,
+		metricsServer:        newMetricsServer(&newLogger, cfg, registry),
 		gtwMux:               gtwMux,
 		errGroup:             errGroup,
 	}
@@ -114,6 +117,10 @@ func (s *Server) Start(ctx context.Context) error {
 		return err
 	}
 
+	if err := s.startMetricsServer(); err != nil {
+		return err
+	}
+
 	// Start additional servers.
 	for _, regServer := range s.registeredServers {
 		regSrv := regServer
@@ -151,6 +158,12 @@ func (s *Server) Stop() error {
 		}
 	}
 
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Shutdown(ctx); err != nil {
+			result = multierror.Append(result, errors.Wrap(err, "failed to stop metrics server"))
+		}
+	}
+
 	// Stop additional servers.
 	for _, registeredServer := range s.registeredServers {
 		err := registeredServer.stop(ctx)
@@ -265,3 +278,25 @@ func (s *Server) startGatewayServer() error {
 
 	return nil
 }
+
+func (s *Server) startMetricsServer() error {
+	if s.metricsServer == nil {
+		return nil
+	}
+
+	metricsListener, err := net.Listen("tcp", s.metricsServer.Addr)
+	if err != nil {
+		return errors.Wrap(err, "metrics socket failed to listen")
+	}
+
+	s.logger.Info().Str("address", "http://"+s.metricsServer.Addr).Msg("Metrics Server starting")
+	s.errGroup.Go(func() error {
+		err := s.metricsServer.Serve(metricsListener)
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return errors.Wrap(err, "metrics server failed to serve")
+	})
+
+	return nil
+}
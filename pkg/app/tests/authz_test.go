@@ -6,6 +6,7 @@ import (
 
 	authz2 "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
 	authz_api_v2 "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
 	"github.com/aserto-dev/topaz/pkg/cc/config"
 	atesting "github.com/aserto-dev/topaz/pkg/testing"
 
@@ -23,6 +24,7 @@ func TestWithMissingIdentity(t *testing.T) {
 	defer harness.Cleanup()
 
 	client := harness.CreateGRPCClient()
+	topazClient := harness.CreateTopazGRPCClient()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -35,6 +37,8 @@ func TestWithMissingIdentity(t *testing.T) {
 		{"TestDecisionTreeWithUserID", DecisionTreeWithUserID(ctx, client)},
 		{"TestIsWithMissingIdentity", IsWithMissingIdentity(ctx, client)},
 		{"TestQueryWithMissingIdentity", QueryWithMissingIdentity(ctx, client)},
+		{"TestIsBatchWithMissingIdentity", IsBatchWithMissingIdentity(ctx, topazClient)},
+		{"TestIsBatchWithUserID", IsBatchWithUserID(ctx, topazClient)},
 	}
 
 	for _, testCase := range tests {
@@ -159,3 +163,67 @@ func QueryWithMissingIdentity(ctx context.Context, client authz2.AuthorizerClien
 		assert.Nil(t, respX, "response object should be nil")
 	}
 }
+
+func IsBatchWithMissingIdentity(ctx context.Context, client topazauthz.AuthorizerClient) func(*testing.T) {
+	return func(t *testing.T) {
+		respX, errX := client.IsBatch(ctx, &topazauthz.IsBatchRequest{
+			PolicyContext: &authz_api_v2.PolicyContext{
+				Path:      "peoplefinder.POST.api.users.__id",
+				Decisions: []string{"allowed"},
+			},
+			IdentityContext: &authz_api_v2.IdentityContext{
+				Identity: "noexisting-user@acmecorp.com",
+				Type:     authz_api_v2.IdentityType_IDENTITY_TYPE_SUB,
+			},
+			Items: []*topazauthz.IsBatchItem{{ResourceContext: &structpb.Struct{}}},
+		})
+
+		if errX != nil {
+			t.Logf("ERR >>> %s\n", errX)
+		}
+
+		if assert.Error(t, errX) {
+			s, ok := status.FromError(errX)
+			assert.Equal(t, ok, true)
+			assert.Equal(t, s.Code(), codes.NotFound)
+		}
+		assert.Nil(t, respX, "response object should be nil")
+	}
+}
+
+func IsBatchWithUserID(ctx context.Context, client topazauthz.AuthorizerClient) func(*testing.T) {
+	return func(t *testing.T) {
+		respX, errX := client.IsBatch(ctx, &topazauthz.IsBatchRequest{
+			PolicyContext: &authz_api_v2.PolicyContext{
+				Path:      "peoplefinder.GET.api.users",
+				Decisions: []string{"allowed"},
+			},
+			IdentityContext: &authz_api_v2.IdentityContext{
+				Identity: "CiQyYmZhYTU1Mi1kOWE1LTQxZTktYTZjMy01YmU2MmI0NDMzYzgSBWxvY2Fs", // April Stewart
+				Type:     authz_api_v2.IdentityType_IDENTITY_TYPE_SUB,
+			},
+			Items: []*topazauthz.IsBatchItem{
+				{ResourceContext: &structpb.Struct{}},
+				{Path: "peoplefinder.GET.api.users.__id"},
+				{Path: "peoplefinder.undefined"},
+			},
+		})
+
+		if errX != nil {
+			t.Logf("ERR >>> %s\n", errX)
+		}
+
+		assert.NoError(t, errX)
+		assert.NotNil(t, respX, "response object should not be nil")
+		assert.Len(t, respX.Results, 3)
+
+		for _, result := range respX.Results[:2] {
+			assert.Nil(t, result.Error)
+			assert.Len(t, result.Decisions, 1)
+		}
+
+		if assert.NotNil(t, respX.Results[2].Error) {
+			assert.Equal(t, int32(codes.InvalidArgument), respX.Results[2].Error.Code)
+		}
+	}
+}
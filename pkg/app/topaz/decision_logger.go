@@ -0,0 +1,36 @@
+package topaz
+
+import (
+	"context"
+
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/logger/fanout"
+	"github.com/aserto-dev/topaz/decision_log/logger/file"
+	"github.com/aserto-dev/topaz/decision_log/logger/grpc"
+	"github.com/aserto-dev/topaz/decision_log/logger/http"
+	"github.com/aserto-dev/topaz/decision_log/logger/nop"
+	"github.com/aserto-dev/topaz/decision_log/logger/spool"
+	"github.com/aserto-dev/topaz/decision_log/logger/store"
+	"github.com/aserto-dev/topaz/pkg/cc/config"
+	"github.com/rs/zerolog"
+)
+
+// nolint: gochecknoinits
+func init() {
+	decisionlog.Register(file.Type, file.Factory{})
+	decisionlog.Register(nop.Type, nop.Factory{})
+	decisionlog.Register(http.Type, http.Factory{})
+	decisionlog.Register(fanout.Type, fanout.Factory{})
+	decisionlog.Register(store.Type, store.Factory{})
+	decisionlog.Register(grpc.Type, grpc.Factory{})
+	decisionlog.Register(spool.Type, spool.Factory{})
+}
+
+// NewDecisionLogger creates the decision logger selected by the decision_logger.type setting.
+func NewDecisionLogger(
+	ctx context.Context,
+	logger *zerolog.Logger,
+	cfg *config.Config) (decisionlog.DecisionLogger, error) {
+
+	return decisionlog.New(ctx, &cfg.DecisionLogger, logger)
+}
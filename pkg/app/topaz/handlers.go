@@ -13,6 +13,7 @@ import (
 	"github.com/aserto-dev/topaz/pkg/cc/config"
 
 	authz2 "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
 )
 
 // GRPCServerRegistrations is where we register implementations with the gRPC server.
@@ -35,6 +36,12 @@ func GatewayServerRegistrations() server.HandlerRegistrations {
 		if err != nil {
 			return errors.Wrap(err, "failed to register authorizer v2 handler with gateway")
 		}
+
+		err = topazauthz.RegisterAuthorizerHandlerFromEndpoint(ctx, mux, grpcEndpoint, opts)
+		if err != nil {
+			return errors.Wrap(err, "failed to register topaz authorizer handler with gateway")
+		}
+
 		return nil
 	}
 }
@@ -26,19 +26,20 @@ func NewRuntimeResolver(
 	logger *zerolog.Logger,
 	cfg *config.Config,
 	decisionLogger decisionlog.DecisionLogger,
-	directoryResolver resolvers.DirectoryResolver) (resolvers.RuntimeResolver, func(), error) {
+	directoryResolver resolvers.DirectoryResolver,
+	hooks []resolvers.RuntimeHook) (resolvers.RuntimeResolver, func(), error) {
 
 	sidecarRuntime, cleanupRuntime, err := runtime.NewRuntime(ctx, logger, &cfg.OPA,
 		// directory get functions
-		runtime.WithBuiltin1(ds.RegisterIdentity(logger, "ds.identity", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterUser(logger, "ds.user", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterObject(logger, "ds.object", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterRelation(logger, "ds.relation", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterGraph(logger, "ds.graph", directoryResolver)),
+		runtime.WithBuiltin1(ds.Recorded(ds.RegisterIdentity(logger, "ds.identity", directoryResolver))),
+		runtime.WithBuiltin1(ds.Recorded(ds.RegisterUser(logger, "ds.user", directoryResolver))),
+		runtime.WithBuiltin1(ds.Recorded(ds.RegisterObject(logger, "ds.object", directoryResolver))),
+		runtime.WithBuiltin1(ds.Recorded(ds.RegisterRelation(logger, "ds.relation", directoryResolver))),
+		runtime.WithBuiltin1(ds.Recorded(ds.RegisterGraph(logger, "ds.graph", directoryResolver))),
 
 		// authorization check functions
-		runtime.WithBuiltin1(ds.RegisterCheckRelation(logger, "ds.check_relation", directoryResolver)),
-		runtime.WithBuiltin1(ds.RegisterCheckPermission(logger, "ds.check_permission", directoryResolver)),
+		runtime.WithBuiltin1(ds.Recorded(ds.RegisterCheckRelation(logger, "ds.check_relation", directoryResolver))),
+		runtime.WithBuiltin1(ds.Recorded(ds.RegisterCheckPermission(logger, "ds.check_permission", directoryResolver))),
 
 		// plugins
 		runtime.WithPlugin(decisionlog_plugin.PluginName, decisionlog_plugin.NewFactory(decisionLogger)),
@@ -47,6 +48,11 @@ func NewRuntimeResolver(
 		return nil, cleanupRuntime, err
 	}
 
+	// the hooks register their compiler triggers before the bundles of the runtime activate.
+	for _, hook := range hooks {
+		hook(sidecarRuntime.GetPluginsManager())
+	}
+
 	err = sidecarRuntime.Start(ctx)
 	if err != nil {
 		return nil, cleanupRuntime, err
@@ -32,7 +32,11 @@ func BuildApp(logOutput logger.Writer, errOutput logger.ErrWriter, configPath co
 	common := &configConfig.Common
 	group := ccCC.ErrGroup
 	resolversResolvers := resolvers.New()
-	authorizerServer := impl.NewAuthorizerServer(zerologLogger, common, resolversResolvers)
+	authorizerServer, err := impl.NewAuthorizerServer(zerologLogger, common, resolversResolvers)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
 	grpcRegistrations, err := GRPCServerRegistrations(context, zerologLogger, configConfig, authorizerServer)
 	if err != nil {
 		cleanup()
@@ -46,7 +50,7 @@ func BuildApp(logOutput logger.Writer, errOutput logger.ErrWriter, configPath co
 		cleanup()
 		return nil, nil, err
 	}
-	serverServer, cleanup2, err := server.NewServer(context, zerologLogger, common, group, grpcRegistrations, handlerRegistrations, httpServer, serveMux)
+	serverServer, cleanup2, err := server.NewServer(context, zerologLogger, common, group, grpcRegistrations, handlerRegistrations, httpServer, serveMux, registerer)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
@@ -79,7 +83,11 @@ func BuildTestApp(logOutput logger.Writer, errOutput logger.ErrWriter, configPat
 	common := &configConfig.Common
 	group := ccCC.ErrGroup
 	resolversResolvers := resolvers.New()
-	authorizerServer := impl.NewAuthorizerServer(zerologLogger, common, resolversResolvers)
+	authorizerServer, err := impl.NewAuthorizerServer(zerologLogger, common, resolversResolvers)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
 	grpcRegistrations, err := GRPCServerRegistrations(context, zerologLogger, configConfig, authorizerServer)
 	if err != nil {
 		cleanup()
@@ -93,7 +101,7 @@ func BuildTestApp(logOutput logger.Writer, errOutput logger.ErrWriter, configPat
 		cleanup()
 		return nil, nil, err
 	}
-	serverServer, cleanup2, err := server.NewServer(context, zerologLogger, common, group, grpcRegistrations, handlerRegistrations, httpServer, serveMux)
+	serverServer, cleanup2, err := server.NewServer(context, zerologLogger, common, group, grpcRegistrations, handlerRegistrations, httpServer, serveMux, registry)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
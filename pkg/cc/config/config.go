@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gobwas/glob"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
@@ -65,6 +66,10 @@ type Common struct {
 		Health struct {
 			ListenAddress string `json:"listen_address"`
 		} `json:"health"`
+		Metrics struct {
+			// Address of the listener serving /metrics, metrics are not served when empty.
+			ListenAddress string `json:"listen_address"`
+		} `json:"metrics"`
 	} `json:"api"`
 
 	JWT struct {
@@ -78,6 +83,139 @@ type Common struct {
 
 	// Default OPA configuration
 	OPA runtime.Config `json:"opa"`
+
+	Authorizer struct {
+		QueryCache struct {
+			// Maximum number of prepared queries cached per runtime, 0 disables the cache.
+			Size int `json:"size"`
+		} `json:"query_cache"`
+		IsBatch struct {
+			// Maximum number of items of a batch evaluated concurrently.
+			MaxConcurrency int `json:"max_concurrency"`
+			// Maximum number of items of a batch request.
+			MaxItems int `json:"max_items"`
+		} `json:"is_batch"`
+		DecisionTree struct {
+			// Maximum number of packages of a decision tree evaluated concurrently.
+			MaxConcurrency int `json:"max_concurrency"`
+			// Return the values of rules that are not booleans, such as strings, numbers and objects.
+			IncludeValues bool `json:"include_values"`
+			// Depth to which rule objects are recursed into, 0 returns the rules of the packages only.
+			MaxDepth int `json:"max_depth"`
+		} `json:"decision_tree"`
+		DecisionCache DecisionCacheConfig `json:"decision_cache"`
+		EvalLimits    EvalLimitsConfig    `json:"eval_limits"`
+		Watch         struct {
+			// Interval at which watched decisions are evaluated again, to observe the changes of directories
+			// that do not notify them, such as remote directories. 0 evaluates them on changes only.
+			Interval time.Duration `json:"interval"`
+		} `json:"watch"`
+	} `json:"authorizer"`
+}
+
+// DecisionCacheConfig configures the cache of the decisions of Is calls.
+type DecisionCacheConfig struct {
+	Enabled bool `json:"enabled"`
+	// TTL of the cached decisions of the paths that match no path rule.
+	TTL time.Duration `json:"ttl"`
+	// Maximum number of cached decisions per runtime.
+	MaxEntries int `json:"max_entries"`
+	// Paths set the TTL of the decisions of matching policy paths, the first matching rule applies.
+	Paths []DecisionCachePath `json:"paths"`
+}
+
+type DecisionCachePath struct {
+	// Path is a glob matched against the policy path, "*" matches a single path
+	// segment and "**" any number of segments, e.g. peoplefinder.GET.**.
+	Path string `json:"path"`
+	// TTL of the cached decisions of the path, 0 disables caching them.
+	TTL time.Duration `json:"ttl"`
+}
+
+// EvalLimitsConfig bounds each policy evaluation of the authorizer calls, so that an expensive
+// policy cannot hold a call, or the directory, for long.
+type EvalLimitsConfig struct {
+	// Time budget of an evaluation, 0 for none.
+	Timeout time.Duration `json:"timeout"`
+	// Time budgets of the evaluations of the is, query and decision_tree calls, which replace the timeout.
+	APIs map[string]time.Duration `json:"apis"`
+	// Maximum number of directory calls made by the ds builtins during an evaluation, 0 for no limit.
+	MaxDSCalls int `json:"max_ds_calls"`
+	// Paths bound the evaluations of matching policy paths further, the first matching rule applies.
+	Paths []EvalLimitsPath `json:"paths"`
+}
+
+type EvalLimitsPath struct {
+	// Path is a glob matched against the policy path, "*" matches a single path
+	// segment and "**" any number of segments, e.g. peoplefinder.GET.**.
+	Path string `json:"path"`
+	// Time budget of the evaluations of the path, the tighter of it and the budget of the call applies, 0 for none.
+	Timeout time.Duration `json:"timeout"`
+	// Maximum number of directory calls of the evaluations of the path, the lower of it and
+	// max_ds_calls applies, 0 for no limit.
+	MaxDSCalls int `json:"max_ds_calls"`
+}
+
+// EvalLimitsAPIs are the authorizer calls whose evaluations can be given their own time budget.
+var EvalLimitsAPIs = []string{"is", "query", "decision_tree"}
+
+func (c *EvalLimitsConfig) validate() error {
+	if c.Timeout < 0 {
+		return errors.New("timeout must be positive or 0")
+	}
+
+	if c.MaxDSCalls < 0 {
+		return errors.New("max_ds_calls must be positive or 0")
+	}
+
+	for name, timeout := range c.APIs {
+		if !contains(EvalLimitsAPIs, name) {
+			return errors.Errorf("apis: unknown api [%s], must be one of %v", name, EvalLimitsAPIs)
+		}
+		if timeout < 0 {
+			return errors.Errorf("apis: %s: timeout must be positive or 0", name)
+		}
+	}
+
+	for i, p := range c.Paths {
+		if _, err := glob.Compile(p.Path, '.'); err != nil {
+			return errors.Wrapf(err, "paths %d: invalid path [%s]", i, p.Path)
+		}
+		if p.Timeout < 0 {
+			return errors.Errorf("paths %d: timeout must be positive or 0", i)
+		}
+		if p.MaxDSCalls < 0 {
+			return errors.Errorf("paths %d: max_ds_calls must be positive or 0", i)
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *DecisionCacheConfig) validate() error {
+	if c.TTL < 0 {
+		return errors.New("ttl must be positive or 0")
+	}
+
+	for i, p := range c.Paths {
+		if _, err := glob.Compile(p.Path, '.'); err != nil {
+			return errors.Wrapf(err, "paths %d: invalid path [%s]", i, p.Path)
+		}
+		if p.TTL < 0 {
+			return errors.Errorf("paths %d: ttl must be positive or 0", i)
+		}
+	}
+
+	return nil
 }
 
 // LoggerConfig is a basic Config copy that gets loaded before everything else,
@@ -137,6 +275,19 @@ func NewConfig(configPath Path, log *zerolog.Logger, overrides Overrider, certsG
 
 	v.SetDefault("opa.max_plugin_wait_time_seconds", "30")
 
+	v.SetDefault("authorizer.query_cache.size", 1000)
+	v.SetDefault("authorizer.is_batch.max_concurrency", 8)
+	v.SetDefault("authorizer.is_batch.max_items", 1000)
+	v.SetDefault("authorizer.decision_tree.max_concurrency", 8)
+	v.SetDefault("authorizer.decision_tree.include_values", false)
+	v.SetDefault("authorizer.decision_tree.max_depth", 0)
+	v.SetDefault("authorizer.decision_cache.enabled", false)
+	v.SetDefault("authorizer.decision_cache.ttl", 10*time.Second)
+	v.SetDefault("authorizer.decision_cache.max_entries", 10000)
+	v.SetDefault("authorizer.eval_limits.timeout", 0)
+	v.SetDefault("authorizer.eval_limits.max_ds_calls", 0)
+	v.SetDefault("authorizer.watch.interval", 0)
+
 	defaults(v)
 
 	configExists, err := fileExists(file)
@@ -3,15 +3,15 @@ package config
 import (
 	"strings"
 
-	"github.com/aserto-dev/topaz/decision_log/logger/file"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Common         `json:",squash"` // nolint:staticcheck // squash is used by mapstructure
-	Auth           AuthnConfig      `json:"auth"`
-	DecisionLogger file.Config      `json:"decision_logger"`
+	Common         `json:",squash"`   // nolint:staticcheck // squash is used by mapstructure
+	Auth           AuthnConfig        `json:"auth"`
+	DecisionLogger decisionlog.Config `json:"decision_logger"`
 }
 
 type AuthnConfig struct {
@@ -52,6 +52,7 @@ func (co *CallOptions) ForPath(path string) *Options {
 }
 
 func defaults(v *viper.Viper) {
+	v.SetDefault("decision_logger.type", "file")
 }
 
 func (c *Config) validation() error {
@@ -62,6 +63,28 @@ func (c *Config) validation() error {
 		return errors.New("opa.config.bundles - too many bundles")
 	}
 
+	if err := decisionlog.Validate(&c.DecisionLogger); err != nil {
+		return errors.Wrap(err, "decision_logger")
+	}
+
+	// a synchronous http decision logger holds every authorization call for the timeouts and
+	// retries of an unreachable collector.
+	if c.DecisionLogger.Type == "http" && !c.DecisionLogger.Async.Enabled {
+		return errors.New("decision_logger: the http decision logger requires async.enabled")
+	}
+
+	if err := c.Authorizer.DecisionCache.validate(); err != nil {
+		return errors.Wrap(err, "authorizer.decision_cache")
+	}
+
+	if err := c.Authorizer.EvalLimits.validate(); err != nil {
+		return errors.Wrap(err, "authorizer.eval_limits")
+	}
+
+	if c.Authorizer.Watch.Interval < 0 {
+		return errors.New("authorizer.watch: interval must be positive or 0")
+	}
+
 	setDefaultCallsAuthz(c)
 
 	if len(c.Auth.APIKeys) > 0 {
@@ -0,0 +1,47 @@
+package clients
+
+import (
+	grpcClient "github.com/aserto-dev/go-aserto/client"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	"github.com/aserto-dev/topaz/pkg/cli/cc"
+)
+
+const localhostAuthorizer = "localhost:8282"
+
+type AuthorizerConfig struct {
+	Host     string `flag:"host" short:"H" help:"authorizer service address" env:"TOPAZ_AUTHORIZER_SVC" default:"localhost:8282"`
+	APIKey   string `flag:"api-key" short:"k" help:"" env:"TOPAZ_AUTHORIZER_KEY"`
+	Insecure bool   `flag:"insecure" short:"i" help:""`
+	TenantID string `flag:"tenant-id" help:""`
+}
+
+// IsLocal reports whether the config addresses the authorizer of the local topaz container.
+func (cfg *AuthorizerConfig) IsLocal() bool {
+	return cfg.Host == "" || cfg.Host == localhostAuthorizer
+}
+
+func NewAuthorizerClient(c *cc.CommonCtx, cfg *AuthorizerConfig) (topazauthz.AuthorizerClient, error) {
+	if cfg.Host == "" {
+		cfg.Host = localhostAuthorizer
+	}
+
+	opts := []grpcClient.ConnectionOption{
+		grpcClient.WithAddr(cfg.Host),
+		grpcClient.WithInsecure(cfg.Insecure),
+	}
+
+	if cfg.APIKey != "" {
+		opts = append(opts, grpcClient.WithAPIKeyAuth(cfg.APIKey))
+	}
+
+	if cfg.TenantID != "" {
+		opts = append(opts, grpcClient.WithTenantID(cfg.TenantID))
+	}
+
+	conn, err := grpcClient.NewConnection(c.Context, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return topazauthz.NewAuthorizerClient(conn.Conn), nil
+}
@@ -15,6 +15,7 @@ var ErrNotRunning = errors.New("topaz is not running, use 'topaz start' or 'topa
 type CLI struct {
 	Backup    BackupCmd    `cmd:"" help:"backup directory data"`
 	Configure ConfigureCmd `cmd:"" help:"configure topaz service"`
+	Decisions DecisionsCmd `cmd:"" help:"search, tail and verify logged decisions"`
 	Export    ExportCmd    `cmd:"" help:"export directory objects"`
 	Install   InstallCmd   `cmd:"" help:"install topaz"`
 	Import    ImportCmd    `cmd:"" help:"import directory objects"`
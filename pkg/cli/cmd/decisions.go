@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aserto-dev/go-authorizer/aserto/authorizer/v2/api"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
+	"github.com/aserto-dev/topaz/decision_log/chain"
+	"github.com/aserto-dev/topaz/decision_log/logger/file"
+	"github.com/aserto-dev/topaz/pkg/cli/cc"
+	"github.com/aserto-dev/topaz/pkg/cli/clients"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type DecisionsCmd struct {
+	Search SearchDecisionsCmd `cmd:"" help:"search logged decisions"`
+	Tail   TailDecisionsCmd   `cmd:"" help:"display the most recent decisions"`
+	Verify VerifyDecisionsCmd `cmd:"" help:"verify the hash chain of decision log files"`
+}
+
+// followPageSize is the number of decisions requested at a time when following new decisions.
+const followPageSize = 100
+
+type DecisionFilters struct {
+	Since   string `flag:"since" help:"only decisions at or after this time, RFC 3339 or a duration such as 15m"`
+	Until   string `flag:"until" help:"only decisions before this time, RFC 3339 or a duration such as 5m"`
+	User    string `flag:"user" short:"u" help:"user id or email"`
+	Path    string `flag:"path" short:"p" help:"policy path, * matches one path segment and ** any number of segments"`
+	Outcome string `flag:"outcome" enum:",allow,deny,none" default:"" help:"allow, deny or none for records without decisions"`
+	Tenant  string `flag:"tenant" help:"tenant id"`
+	JSON    bool   `flag:"json" help:"print decisions as JSON"`
+}
+
+type SearchDecisionsCmd struct {
+	DecisionFilters
+	Limit int `flag:"limit" short:"l" default:"100" help:"maximum number of decisions"`
+	clients.AuthorizerConfig
+}
+
+func (cmd *SearchDecisionsCmd) Run(c *cc.CommonCtx) error {
+	if cmd.IsLocal() {
+		if err := CheckRunning(c); err != nil {
+			return err
+		}
+	}
+
+	client, err := clients.NewAuthorizerClient(c, &cmd.AuthorizerConfig)
+	if err != nil {
+		return err
+	}
+
+	req, err := cmd.request(time.Now())
+	if err != nil {
+		return err
+	}
+	req.Limit = int32(cmd.Limit)
+
+	resp, err := client.SearchDecisions(c.Context, req)
+	if err != nil {
+		return err
+	}
+
+	return printDecisions(c.UI.Output(), resp.Decisions, cmd.JSON)
+}
+
+type TailDecisionsCmd struct {
+	DecisionFilters
+	Lines    int           `flag:"lines" short:"n" default:"10" help:"number of decisions to display"`
+	Follow   bool          `flag:"follow" short:"f" help:"keep displaying new decisions as they are logged"`
+	Interval time.Duration `flag:"interval" default:"2s" help:"polling interval when following"`
+	Lag      time.Duration `flag:"lag" default:"1m" help:"how long after its timestamp a decision may be logged and still be displayed when following"`
+	clients.AuthorizerConfig
+}
+
+func (cmd *TailDecisionsCmd) Run(c *cc.CommonCtx) error {
+	if cmd.IsLocal() {
+		if err := CheckRunning(c); err != nil {
+			return err
+		}
+	}
+
+	client, err := clients.NewAuthorizerClient(c, &cmd.AuthorizerConfig)
+	if err != nil {
+		return err
+	}
+
+	req, err := cmd.request(time.Now())
+	if err != nil {
+		return err
+	}
+	req.Limit = int32(cmd.Lines)
+
+	resp, err := client.SearchDecisions(c.Context, req)
+	if err != nil {
+		return err
+	}
+
+	// the most recent decisions are returned first, display them in chronological order.
+	decisions := resp.Decisions
+	chronological(decisions)
+	if err := printDecisions(c.UI.Output(), decisions, cmd.JSON); err != nil {
+		return err
+	}
+
+	if !cmd.Follow {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt)
+	defer stop()
+
+	return cmd.follow(ctx, c, client, req, decisions)
+}
+
+// follow displays the decisions logged after the ones already displayed, oldest first.
+//
+// Decisions are stamped before they are logged, so concurrent calls and queued or batched
+// decision loggers may write a decision after a more recent one was displayed. Every poll
+// therefore searches again from the lag before the most recent displayed decision, and skips
+// the decisions it already displayed.
+func (cmd *TailDecisionsCmd) follow(
+	ctx context.Context,
+	c *cc.CommonCtx,
+	client topazauthz.AuthorizerClient,
+	req *topazauthz.SearchDecisionsRequest,
+	displayed []*api.Decision,
+) error {
+	since := time.Now()
+	if req.From != nil {
+		since = req.From.AsTime()
+	}
+
+	latest := since
+	seen := map[string]time.Time{}
+	for _, d := range displayed {
+		ts := d.GetTimestamp().AsTime()
+		seen[d.Id] = ts
+		if ts.After(latest) {
+			latest = ts
+		}
+	}
+
+	req.OldestFirst = true
+	req.Limit = followPageSize
+
+	for {
+		from := latest.Add(-cmd.Lag)
+		if from.Before(since) {
+			from = since
+		}
+		req.From = timestamppb.New(from)
+		req.PageToken = ""
+
+		for {
+			resp, err := client.SearchDecisions(ctx, req)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+
+			var fresh []*api.Decision
+			for _, d := range resp.Decisions {
+				if _, ok := seen[d.Id]; ok {
+					continue
+				}
+				ts := d.GetTimestamp().AsTime()
+				seen[d.Id] = ts
+				if ts.After(latest) {
+					latest = ts
+				}
+				fresh = append(fresh, d)
+			}
+
+			if err := printDecisions(c.UI.Output(), fresh, cmd.JSON); err != nil {
+				return err
+			}
+
+			if len(resp.Decisions) < followPageSize {
+				break
+			}
+			req.PageToken = resp.NextPageToken
+		}
+
+		// decisions stamped before the window can no longer be returned.
+		for id, ts := range seen {
+			if ts.Before(from) {
+				delete(seen, id)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(cmd.Interval):
+		}
+	}
+}
+
+type VerifyDecisionsCmd struct {
+	PublicKey string   `flag:"public-key" required:"" help:"PEM encoded Ed25519 public key of the checkpoint signing key"`
+	Files     []string `arg:"" name:"log-file" help:"file decision log, its rotated files are verified along with it"`
+}
+
+func (cmd *VerifyDecisionsCmd) Run(c *cc.CommonCtx) error {
+	key, err := chain.LoadPublicKey(cmd.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	v := chain.NewVerifier(key)
+	for _, f := range cmd.Files {
+		if err := file.ReadLogSet(f, v.Add); err != nil {
+			return err
+		}
+	}
+
+	report := v.Report()
+	if len(report.Chains) == 0 && report.OK() {
+		return errors.Errorf("no decision records found in %v", cmd.Files)
+	}
+
+	w := c.UI.Output()
+
+	for _, s := range report.Chains {
+		fmt.Fprintf(w, "chain %s: %d records, %d checkpoints, %s to %s\n",
+			s.ChainID, s.Records, s.Checkpoints,
+			s.First.Format(time.RFC3339), s.Last.Format(time.RFC3339),
+		)
+		if s.Continues != "" {
+			fmt.Fprintf(w, "  continues chain %s\n", s.Continues)
+		}
+	}
+
+	for _, i := range report.Issues {
+		fmt.Fprintln(w, i.String())
+	}
+
+	if !report.OK() {
+		return errors.Errorf("decision log verification failed, %d issues found", len(report.Issues))
+	}
+
+	fmt.Fprintln(w, "decision log verified")
+	return nil
+}
+
+func (f *DecisionFilters) request(now time.Time) (*topazauthz.SearchDecisionsRequest, error) {
+	req := &topazauthz.SearchDecisionsRequest{
+		User:     f.User,
+		Path:     f.Path,
+		Outcome:  f.Outcome,
+		TenantId: f.Tenant,
+	}
+
+	since, err := parseTime(f.Since, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid --since")
+	}
+	if !since.IsZero() {
+		req.From = timestamppb.New(since)
+	}
+
+	until, err := parseTime(f.Until, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid --until")
+	}
+	if !until.IsZero() {
+		req.To = timestamppb.New(until)
+	}
+
+	return req, nil
+}
+
+// parseTime accepts an RFC 3339 time or a duration, which is subtracted from now.
+func parseTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Parse(time.RFC3339Nano, v)
+}
+
+func chronological(decisions []*api.Decision) {
+	sort.SliceStable(decisions, func(i, j int) bool {
+		return decisions[i].GetTimestamp().AsTime().Before(decisions[j].GetTimestamp().AsTime())
+	})
+}
+
+func printDecisions(w io.Writer, decisions []*api.Decision, asJSON bool) error {
+	for _, d := range decisions {
+		if asJSON {
+			b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(d)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, string(b))
+			continue
+		}
+
+		user := d.GetUser().GetEmail()
+		if user == "" {
+			user = d.GetUser().GetId()
+		}
+		if user == "" {
+			user = "-"
+		}
+
+		outcomes := make([]string, 0, len(d.Outcomes))
+		for k, v := range d.Outcomes {
+			outcomes = append(outcomes, fmt.Sprintf("%s=%t", k, v))
+		}
+		sort.Strings(outcomes)
+
+		fmt.Fprintf(w, "%s  %-5s  %-30s  %s  %s\n",
+			d.GetTimestamp().AsTime().Format(time.RFC3339),
+			strings.ToUpper(decisionlog.Outcome(d)),
+			user,
+			d.Path,
+			strings.Join(outcomes, " "),
+		)
+	}
+
+	return nil
+}
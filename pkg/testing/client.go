@@ -11,6 +11,7 @@ import (
 	"os"
 
 	authz2 "github.com/aserto-dev/go-authorizer/aserto/authorizer/v2"
+	topazauthz "github.com/aserto-dev/topaz/api/authz/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -57,6 +58,16 @@ func (h *EngineHarness) Req(verb, path, tenantID, body string) (string, int) {
 }
 
 func (h *EngineHarness) CreateGRPCClient() authz2.AuthorizerClient {
+	return authz2.NewAuthorizerClient(h.grpcConn())
+}
+
+// CreateTopazGRPCClient creates a client of the authorization calls topaz provides
+// in addition to the authorizer v2 service.
+func (h *EngineHarness) CreateTopazGRPCClient() topazauthz.AuthorizerClient {
+	return topazauthz.NewAuthorizerClient(h.grpcConn())
+}
+
+func (h *EngineHarness) grpcConn() *grpc.ClientConn {
 	var opts []grpc.DialOption
 	var tlsConf tls.Config
 	certPool := x509.NewCertPool()
@@ -77,5 +88,5 @@ func (h *EngineHarness) CreateGRPCClient() authz2.AuthorizerClient {
 	if err != nil {
 		h.t.Fatal(err)
 	}
-	return authz2.NewAuthorizerClient(conn)
+	return conn
 }
@@ -8,7 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/aserto-dev/runtime"
-	"github.com/aserto-dev/topaz/decision_log/logger/file"
+	decisionlog "github.com/aserto-dev/topaz/decision_log"
 	"github.com/aserto-dev/topaz/pkg/app"
 	"github.com/aserto-dev/topaz/pkg/app/topaz"
 	"github.com/aserto-dev/topaz/pkg/cc/config"
@@ -24,8 +24,9 @@ type EngineHarness struct {
 	Engine      *app.Authorizer
 	LogDebugger *LogDebugger
 
-	cleanup func()
-	t       *testing.T
+	decisionLogger decisionlog.DecisionLogger
+	cleanup        func()
+	t              *testing.T
 }
 
 // Cleanup releases all resources the harness uses and
@@ -39,6 +40,7 @@ func (h *EngineHarness) Cleanup() {
 	}
 
 	h.cleanup()
+	h.decisionLogger.Shutdown()
 
 	assert.Eventually(func() bool {
 		return !PortOpen("127.0.0.1:8484")
@@ -94,9 +96,9 @@ func setup(t *testing.T, configOverrides func(*config.Config), online bool) *Eng
 	)
 	assert.NoError(err)
 	directory := topaz.DirectoryResolver(h.Engine.Context, h.Engine.Logger, h.Engine.Configuration)
-	decisionlog, err := file.New(h.Engine.Context, &h.Engine.Configuration.DecisionLogger, h.Engine.Logger)
+	h.decisionLogger, err = topaz.NewDecisionLogger(h.Engine.Context, h.Engine.Logger, h.Engine.Configuration)
 	assert.NoError(err)
-	rt, _, err := topaz.NewRuntimeResolver(h.Engine.Context, h.Engine.Logger, h.Engine.Configuration, decisionlog, directory)
+	rt, _, err := topaz.NewRuntimeResolver(h.Engine.Context, h.Engine.Logger, h.Engine.Configuration, h.decisionLogger, directory, h.Engine.Resolver.GetRuntimeHooks())
 	assert.NoError(err)
 	h.Engine.Resolver.SetRuntimeResolver(rt)
 	h.Engine.Resolver.SetDirectoryResolver(directory)
@@ -0,0 +1,8 @@
+package resolvers
+
+// DirectoryWatcher notifies of changes to the objects, relations and types of the directory.
+type DirectoryWatcher interface {
+	// Watch returns a channel that receives a value after the directory changes, changes made
+	// before the value is received are coalesced, and a function that ends the watch.
+	Watch() (<-chan struct{}, func())
+}
@@ -3,6 +3,8 @@ package resolvers
 type Resolvers struct {
 	runtimeResolver   RuntimeResolver
 	directoryResolver DirectoryResolver
+	directoryWatcher  DirectoryWatcher
+	runtimeHooks      []RuntimeHook
 }
 
 func New() *Resolvers {
@@ -24,3 +26,21 @@ func (s *Resolvers) SetDirectoryResolver(resolver DirectoryResolver) {
 func (s *Resolvers) GetDirectoryResolver() DirectoryResolver {
 	return s.directoryResolver
 }
+
+func (s *Resolvers) SetDirectoryWatcher(watcher DirectoryWatcher) {
+	s.directoryWatcher = watcher
+}
+
+// GetDirectoryWatcher returns the watcher of the directory, or nil when changes to the directory are not observed.
+func (s *Resolvers) GetDirectoryWatcher() DirectoryWatcher {
+	return s.directoryWatcher
+}
+
+func (s *Resolvers) AddRuntimeHook(hook RuntimeHook) {
+	s.runtimeHooks = append(s.runtimeHooks, hook)
+}
+
+// GetRuntimeHooks returns the hooks to call with the plugins manager of every runtime that is created.
+func (s *Resolvers) GetRuntimeHooks() []RuntimeHook {
+	return s.runtimeHooks
+}
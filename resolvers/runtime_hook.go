@@ -0,0 +1,7 @@
+package resolvers
+
+import "github.com/open-policy-agent/opa/plugins"
+
+// RuntimeHook is called with the plugins manager of a runtime once the runtime is created, before
+// its plugins start and its bundles activate, to register the compiler triggers of the manager.
+type RuntimeHook func(m *plugins.Manager)